@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagBulkIDs               []int64
+	flagBulkFilter            db.FileFilter
+	flagBulkAddLabel          string
+	flagBulkRemoveLabel       string
+	flagBulkSetStatus         string
+	flagBulkAppendDescription string
+	flagBulkDryRun            bool
+)
+
+func init() {
+	f := bulkCmd.Flags()
+	f.Int64SliceVar(&flagBulkIDs, "id", nil, "Select files by ID (repeat or separate with commas)")
+	f.StringVar(&flagBulkFilter.Label, "label", "", "Select files carrying this label")
+	f.StringVar(&flagBulkFilter.NoLabel, "nolabel", "", "Select files where this label is marked absent")
+	f.StringVar(&flagBulkFilter.MediaType, "type", "", "Select files of this media type")
+	f.StringVar(&flagBulkFilter.Path, "path", "", "Select files whose path matches this glob, e.g. 2024/*.jpg")
+	f.StringVar(&flagBulkFilter.Status, "status", "", "Select files with this workflow status ("+strings.Join(db.Statuses, ", ")+")")
+	f.StringVar(&flagBulkFilter.Description, "desc", "", "Select files whose description contains this text")
+	f.StringVarP(&flagBulkFilter.Query, "query", "q", "", "Select files matching this query, as in jli query")
+
+	f.StringVar(&flagBulkAddLabel, "add-label", "", "Add this label to every selected file")
+	f.StringVar(&flagBulkRemoveLabel, "remove-label", "", "Remove this label from every selected file")
+	f.StringVar(&flagBulkSetStatus, "set-status", "", "Set the workflow status of every selected file")
+	f.StringVar(&flagBulkAppendDescription, "append-description", "", "Append a line to the description of every selected file")
+	f.BoolVarP(&flagBulkDryRun, "dry-run", "n", false, "Only print how many files would change")
+	rootCmd.AddCommand(bulkCmd)
+}
+
+var bulkCmd = &cobra.Command{
+	Use:   "bulk [directory]",
+	Short: "Add or remove a label, set the status or append to the description of many files",
+	Long: `Add or remove a label, set the status or append to the description of many files.
+
+Files are selected by ID, by filter or both; every change is made in a
+single transaction. For example:
+
+  jli bulk --path 'cats/*' --add-label cat ~/photos
+  jli bulk --label blurry --set-status skipped --dry-run ~/photos
+  jli bulk --query 'type:video keyframes<2' --set-status todo ~/videos`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		sel := db.Selection{IDs: flagBulkIDs, Filter: flagBulkFilter}
+		edit := db.BulkEdit{
+			AddLabel:          flagBulkAddLabel,
+			RemoveLabel:       flagBulkRemoveLabel,
+			Status:            flagBulkSetStatus,
+			AppendDescription: flagBulkAppendDescription,
+		}
+		if edit.IsZero() && !flagBulkDryRun {
+			return fmt.Errorf("nothing to change: give --add-label, --remove-label, --set-status or --append-description")
+		}
+
+		database, err := openDatabase(dir)
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		if flagBulkDryRun {
+			count, err := database.CountSelection(sel)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Would change %d files\n", count)
+			return nil
+		}
+
+		count, err := database.ApplyBulkEdit(sel, edit)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Changed %d files\n", count)
+		return nil
+	},
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/monorkin/just-label-it/internal/export"
+	"github.com/monorkin/just-label-it/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagExportFormat   string
+	flagExportOutput   string
+	flagExportFPS      float64
+	flagExportStatus   string
+	flagExportQuery    string
+	flagExportApproved bool
+)
+
+func init() {
+	var names []string
+	for _, f := range export.Formats {
+		names = append(names, f.Name)
+	}
+
+	exportCmd.Flags().StringVarP(&flagExportFormat, "format", "f", "jsonl", "Export format ("+strings.Join(names, ", ")+")")
+	exportCmd.Flags().StringVarP(&flagExportOutput, "output", "o", "", "Directory to write the export into")
+	exportCmd.Flags().Float64Var(&flagExportFPS, "fps", export.DefaultFrameRate, "Frame rate used to number video frames (mot, coco-video)")
+	exportCmd.Flags().StringVar(&flagExportStatus, "status", "", "Only export files with this workflow status ("+strings.Join(db.Statuses, ", ")+")")
+	exportCmd.Flags().StringVarP(&flagExportQuery, "query", "q", "", "Only export files matching this query, as in jli query")
+	exportCmd.Flags().BoolVar(&flagExportApproved, "approved", false, "Only export files a reviewer approved")
+	exportCmd.MarkFlagRequired("output")
+	rootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [directory]",
+	Short: "Export labels, keyframes, regions, spans, tracks and comparisons as a dataset",
+	Long:  exportLong(),
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		format, ok := export.Lookup(flagExportFormat)
+		if !ok {
+			return fmt.Errorf("unknown export format %q", flagExportFormat)
+		}
+		if flagExportFPS <= 0 {
+			return fmt.Errorf("frame rate must be positive")
+		}
+		cfg, err := project.Load(dir)
+		if err != nil {
+			return err
+		}
+
+		filter := db.FileFilter{Status: flagExportStatus, Query: flagExportQuery, Fields: cfg.Fields}
+		if flagExportApproved {
+			filter.Review = db.ReviewApproved
+		}
+		if err := filter.Validate(); err != nil {
+			return err
+		}
+
+		database, err := openDatabase(dir)
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		ds, err := export.Load(database, dir, filter)
+		if err != nil {
+			return err
+		}
+
+		ds.FrameRate = flagExportFPS
+
+		if err := format.Write(ds, flagExportOutput); err != nil {
+			return fmt.Errorf("writing %s export: %w", format.Name, err)
+		}
+
+		fmt.Printf("Exported %d files as %s to %s\n", len(ds.Items), format.Name, flagExportOutput)
+		return nil
+	},
+}
+
+// exportLong describes the available formats for the command's help text.
+func exportLong() string {
+	var b strings.Builder
+	b.WriteString("Export labels, keyframes, regions, spans, tracks and comparisons as a dataset.\n\nFormats:\n")
+	for _, f := range export.Formats {
+		fmt.Fprintf(&b, "  %-14s %s\n", f.Name, f.Description)
+	}
+	return b.String()
+}
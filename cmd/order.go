@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagOrderSet  string
+	flagOrderSeed int64
+)
+
+func init() {
+	orderCmd.Flags().StringVar(&flagOrderSet, "set", "", "Navigate in this order ("+strings.Join(db.Orders, ", ")+")")
+	orderCmd.Flags().Int64Var(&flagOrderSeed, "seed", 0, "Seed of the shuffle order (random if not given)")
+	rootCmd.AddCommand(orderCmd)
+}
+
+var orderCmd = &cobra.Command{
+	Use:   "order [directory]",
+	Short: "Print or change the order files are navigated in",
+	Long: `Print or change the order files are navigated in.
+
+The order is kept in the project, so the viewer's counter and arrows stay
+the same across restarts. Files are ordered by path, shuffled, from the
+least recently modified, from the smallest, from the shortest video or
+audio, or from the one with the fewest labels. Files are ranked when the
+order is set, so labeling doesn't move them; set the order again to rank
+them anew.
+
+The length of a video or audio file is only known once it has been
+opened in the viewer, so the duration order ranks the opened files and
+puts all others last, by path. Set the order again after opening more
+files to rank them too. For example:
+
+  jli order --set shuffle --seed 42 ~/photos`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		database, err := openDatabase(dir)
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		if flagOrderSet != "" {
+			order := db.Order{Name: flagOrderSet, Seed: flagOrderSeed}
+			if order.Name == db.OrderShuffle && !cmd.Flags().Changed("seed") {
+				order.Seed = rand.Int64N(1 << 31)
+			}
+			if err := database.SetNavigationOrder(order); err != nil {
+				return err
+			}
+		}
+
+		order, err := database.NavigationOrder()
+		if err != nil {
+			return err
+		}
+		if order.Name == db.OrderShuffle {
+			fmt.Printf("%s (seed %d)\n", order.Name, order.Seed)
+		} else {
+			fmt.Println(order.Name)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/monorkin/just-label-it/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var flagQueryJSON bool
+
+func init() {
+	queryCmd.Flags().BoolVar(&flagQueryJSON, "json", false, "Print the matching files as JSON")
+	rootCmd.AddCommand(queryCmd)
+}
+
+var queryCmd = &cobra.Command{
+	Use:   "query QUERY [directory]",
+	Short: "Print the paths of the files matching a query",
+	Long: `Print the paths of the files matching a query.
+
+Terms are separated by spaces and must all match; a leading "-" negates
+a term:
+
+  label:NAME        carries the label
+  type:TYPE         media type: image, video, audio or text
+  status:STATUS     workflow status
+  review:REVIEW     approved, rejected, or pending: done but not yet reviewed
+  path:GLOB         path matches the glob, e.g. path:2024/**
+  desc:TEXT         description contains the text
+  field:NAME>VALUE  form field compares to the value (=, !=, and >, >=, <, <=
+                    on numbers and text), typed as the field is declared
+  keyframes>N       count of keyframes, labels, regions, segments, spans
+                    or tracks compares to N (=, >, >=, <, <=)
+
+Any other word or "quoted phrase" must appear in the description or a
+description slot.
+For example:
+
+  jli query 'label:dog -label:cat type:video desc:"on a leash" keyframes>3' ~/videos
+
+A query starting with a negated term follows "--", so it isn't read as
+a flag:
+
+  jli query -- '-label:cat type:image' ~/photos`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 1 {
+			dir = args[1]
+		}
+
+		cfg, err := project.Load(dir)
+		if err != nil {
+			return err
+		}
+
+		filter := db.FileFilter{Query: args[0], Fields: cfg.Fields}
+		if err := filter.Validate(); err != nil {
+			return err
+		}
+
+		database, err := openDatabase(dir)
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		files, err := database.MediaFiles(filter)
+		if err != nil {
+			return err
+		}
+
+		if flagQueryJSON {
+			if files == nil {
+				files = []db.MediaFile{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(files)
+		}
+
+		for _, f := range files {
+			fmt.Println(f.Path)
+		}
+		return nil
+	},
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 
 	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/monorkin/just-label-it/internal/project"
 	"github.com/monorkin/just-label-it/internal/scanner"
 	"github.com/monorkin/just-label-it/internal/server"
 	"github.com/spf13/cobra"
@@ -17,12 +18,13 @@ import (
 var (
 	flagBind string
 	flagPort int
+	flagUser string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "jli [directory]",
 	Short: "Label media files for LLM training sets",
-	Long:  "Just Label It — a tool for classifying images, video, and audio files with tags and descriptions.",
+	Long:  "Just Label It — a tool for classifying images, video, audio, and text files with tags and descriptions.",
 	Args:  cobra.MaximumNArgs(1),
 	RunE:  runOpen,
 }
@@ -30,6 +32,15 @@ var rootCmd = &cobra.Command{
 func init() {
 	rootCmd.PersistentFlags().StringVar(&flagBind, "bind", "127.0.0.1", "Address to bind the server to")
 	rootCmd.PersistentFlags().IntVar(&flagPort, "port", 0, "Port to listen on (0 for auto)")
+	rootCmd.PersistentFlags().StringVar(&flagUser, "user", defaultUser(), "Annotator name recorded with judgments")
+}
+
+// defaultUser returns the login name of the current user, if known.
+func defaultUser() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
 }
 
 // Execute runs the root command.
@@ -39,9 +50,28 @@ func Execute() {
 	}
 }
 
+// openDatabase opens the jli.db of an existing project without rescanning it.
+func openDatabase(dir string) (*db.DB, error) {
+	dbPath := filepath.Join(dir, "jli.db")
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, fmt.Errorf("no jli.db found in %s (run jli there first): %w", dir, err)
+	}
+
+	database, err := db.Open(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	return database, nil
+}
+
 // startServer initializes the database, scans for media files, and starts the HTTP server.
 // It returns the listener address so callers can open a browser if desired.
 func startServer(dir string) (net.Listener, *http.Server, error) {
+	cfg, err := project.Load(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	dbPath := filepath.Join(dir, "jli.db")
 	database, err := db.Open(dbPath)
 	if err != nil {
@@ -55,15 +85,20 @@ func startServer(dir string) (net.Listener, *http.Server, error) {
 	}
 
 	for _, f := range files {
-		if err := database.UpsertMediaFile(f.Path, f.MediaType); err != nil {
+		if err := database.UpsertMediaFile(f.Path, f.MediaType, f.Size, f.ModTime); err != nil {
 			log.Printf("warning: skipping %s: %v", f.Path, err)
 		}
 	}
 
+	if err := database.RefreshNavigationOrder(); err != nil {
+		database.Close()
+		return nil, nil, fmt.Errorf("ranking media files: %w", err)
+	}
+
 	count, _ := database.MediaFileCount()
 	log.Printf("Found %d media files in %s", count, dir)
 
-	handler, err := server.New(database, dir)
+	handler, err := server.New(database, dir, cfg, flagUser)
 	if err != nil {
 		database.Close()
 		return nil, nil, fmt.Errorf("creating server: %w", err)
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/monorkin/just-label-it/internal/project"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flagStatsJSON   bool
+	flagStatsStatus string
+	flagStatsQuery  string
+)
+
+func init() {
+	statsCmd.Flags().BoolVar(&flagStatsJSON, "json", false, "Print statistics as JSON")
+	statsCmd.Flags().StringVar(&flagStatsStatus, "status", "", "Only count files with this workflow status ("+strings.Join(db.Statuses, ", ")+")")
+	statsCmd.Flags().StringVarP(&flagStatsQuery, "query", "q", "", "Only count files matching this query, as in jli query")
+	rootCmd.AddCommand(statsCmd)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [directory]",
+	Short: "Print label statistics for a project",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+
+		cfg, err := project.Load(dir)
+		if err != nil {
+			return err
+		}
+
+		filter := db.FileFilter{Status: flagStatsStatus, Query: flagStatsQuery, Fields: cfg.Fields}
+		if err := filter.Validate(); err != nil {
+			return err
+		}
+
+		database, err := openDatabase(dir)
+		if err != nil {
+			return err
+		}
+		defer database.Close()
+
+		stats, err := database.Stats(filter)
+		if err != nil {
+			return err
+		}
+
+		if flagStatsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(stats)
+		}
+
+		printStats(stats)
+		return nil
+	},
+}
+
+// printStats writes a plain-text report of the project statistics to stdout.
+func printStats(stats *db.Stats) {
+	fmt.Printf("Files: %d\n", stats.TotalFiles)
+	fmt.Printf("Without labels: %d (%.1f%%)\n", stats.UnlabeledFiles, stats.UnlabeledPercent())
+	fmt.Printf("Without description: %d (%.1f%%)\n", stats.UndescribedFiles, stats.UndescribedPercent())
+
+	fmt.Printf("Finished: %d (%.1f%%)\n", stats.Progress.Finished(), stats.Progress.FinishedPercent())
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "\nSTATUS\tFILES")
+	for _, status := range db.Statuses {
+		fmt.Fprintf(tw, "%s\t%d\n", status, stats.Progress.ByStatus[status])
+	}
+
+	fmt.Fprintln(tw, "\nTYPE\tFILES\tLABELED\tCOVERAGE")
+	for _, m := range stats.MediaTypes {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\n", m.MediaType, m.Files, m.Labeled, m.Coverage())
+	}
+
+	fmt.Fprintln(tw, "\nLABEL\tFILES\tKEYFRAMES\tNEGATED")
+	for _, l := range stats.Labels {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", l.Label.Name, l.Files, l.Keyframes, l.NegatedFiles)
+	}
+
+	if len(stats.Cooccurrence) > 0 {
+		fmt.Fprintln(tw, "\nLABEL\tLABEL\tFILES")
+		for _, p := range stats.Cooccurrence {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", p.A, p.B, p.Files)
+		}
+	}
+
+	fmt.Fprintln(tw, "\nDIRECTORY\tFILES\tLABELS")
+	for _, d := range stats.Directories {
+		names := make([]string, 0, len(d.Labels))
+		for name := range d.Labels {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+
+		counts := make([]string, len(names))
+		for i, name := range names {
+			counts[i] = fmt.Sprintf("%s=%d", name, d.Labels[name])
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", d.Directory, d.Files, strings.Join(counts, " "))
+	}
+
+	tw.Flush()
+}
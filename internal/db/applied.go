@@ -0,0 +1,127 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLabelNotAssigned is returned when changing a label that an annotation
+// doesn't carry.
+var ErrLabelNotAssigned = errors.New("label not assigned")
+
+// LabelTarget is a kind of annotation that labels are applied to: media
+// files, keyframes, regions, segments or spans. Each keeps its labels in
+// its own table, keyed by the annotation and the label.
+type LabelTarget struct {
+	Name   string // What carries the labels, e.g. "keyframe".
+	table  string
+	column string // Column of the table holding the annotation's ID.
+}
+
+// The kinds of annotations labels are applied to.
+var (
+	MediaFileLabels = LabelTarget{Name: "media file", table: "media_labels", column: "media_file_id"}
+	KeyframeLabels  = LabelTarget{Name: "keyframe", table: "keyframe_labels", column: "keyframe_id"}
+	RegionLabels    = LabelTarget{Name: "region", table: "region_labels", column: "region_id"}
+	SegmentLabels   = LabelTarget{Name: "segment", table: "segment_labels", column: "segment_id"}
+	SpanLabels      = LabelTarget{Name: "span", table: "span_labels", column: "span_id"}
+)
+
+// AddAppliedLabel applies a label to an annotation. A negated label asserts
+// that the label doesn't apply. Adding an existing label updates its polarity.
+func (d *DB) AddAppliedLabel(t LabelTarget, id, labelID int64, negated bool) error {
+	_, err := d.conn.Exec(
+		`INSERT INTO `+t.table+` (`+t.column+`, label_id, negated) VALUES (?, ?, ?)
+		 ON CONFLICT DO UPDATE SET negated = excluded.negated`,
+		id, labelID, negated,
+	)
+	if err != nil {
+		return fmt.Errorf("adding label %d to %s %d: %w", labelID, t.Name, id, err)
+	}
+	return nil
+}
+
+// GetAppliedLabel returns a single label of an annotation, or nil if the
+// annotation doesn't carry it.
+func (d *DB) GetAppliedLabel(t LabelTarget, id, labelID int64) (*AppliedLabel, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+labelColumns+`, a.attributes, a.negated FROM labels l
+		 JOIN `+t.table+` a ON a.label_id = l.id
+		 WHERE a.`+t.column+` = ? AND a.label_id = ?`,
+		id, labelID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching label %d of %s %d: %w", labelID, t.Name, id, err)
+	}
+
+	labels, err := scanAppliedLabels(rows)
+	if err != nil || len(labels) == 0 {
+		return nil, err
+	}
+	return &labels[0], nil
+}
+
+// AppliedLabels returns all labels of an annotation, with their attributes.
+func (d *DB) AppliedLabels(t LabelTarget, id int64) ([]AppliedLabel, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+labelColumns+`, a.attributes, a.negated FROM labels l
+		 JOIN `+t.table+` a ON a.label_id = l.id
+		 WHERE a.`+t.column+` = ?
+		 ORDER BY l.name ASC`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching labels for %s %d: %w", t.Name, id, err)
+	}
+	return scanAppliedLabels(rows)
+}
+
+// SetAppliedLabelNegated changes the polarity of a label on an annotation.
+func (d *DB) SetAppliedLabelNegated(t LabelTarget, id, labelID int64, negated bool) error {
+	result, err := d.conn.Exec(
+		`UPDATE `+t.table+` SET negated = ? WHERE `+t.column+` = ? AND label_id = ?`,
+		negated, id, labelID,
+	)
+	if err != nil {
+		return fmt.Errorf("updating polarity of label %d on %s %d: %w", labelID, t.Name, id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%w: label %d on %s %d", ErrLabelNotAssigned, labelID, t.Name, id)
+	}
+	return nil
+}
+
+// SetAppliedLabelAttributes validates and replaces the attributes of a
+// label on an annotation.
+func (d *DB) SetAppliedLabelAttributes(t LabelTarget, id, labelID int64, attrs map[string]any) (Attributes, error) {
+	validated, err := d.ValidateAttributes(labelID, attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := d.conn.Exec(
+		`UPDATE `+t.table+` SET attributes = ? WHERE `+t.column+` = ? AND label_id = ?`,
+		validated, id, labelID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("updating attributes of label %d on %s %d: %w", labelID, t.Name, id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return nil, fmt.Errorf("%w: label %d on %s %d", ErrLabelNotAssigned, labelID, t.Name, id)
+	}
+	return validated, nil
+}
+
+// RemoveAppliedLabel removes a label from an annotation.
+func (d *DB) RemoveAppliedLabel(t LabelTarget, id, labelID int64) error {
+	_, err := d.conn.Exec(
+		`DELETE FROM `+t.table+` WHERE `+t.column+` = ? AND label_id = ?`,
+		id, labelID,
+	)
+	if err != nil {
+		return fmt.Errorf("removing label %d from %s %d: %w", labelID, t.Name, id, err)
+	}
+	return nil
+}
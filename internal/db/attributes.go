@@ -0,0 +1,248 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAttributes is returned when attributes don't match a label's attribute schema.
+var ErrInvalidAttributes = errors.New("invalid attributes")
+
+// Attribute value types supported by label attribute schemas.
+const (
+	AttributeInt    = "int"
+	AttributeFloat  = "float"
+	AttributeEnum   = "enum"
+	AttributeString = "string"
+)
+
+// Attributes holds the qualifiers attached to a single label assignment,
+// e.g. severity=3 or confidence=low. It is stored as a JSON object.
+type Attributes map[string]any
+
+// Scan implements sql.Scanner.
+func (a *Attributes) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		*a = Attributes{}
+		return nil
+	default:
+		return fmt.Errorf("scanning attributes: unsupported type %T", src)
+	}
+
+	attrs := Attributes{}
+	if err := json.Unmarshal(raw, &attrs); err != nil {
+		return fmt.Errorf("scanning attributes: %w", err)
+	}
+	*a = attrs
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// AppliedLabel is a label as assigned to a media file or keyframe,
+// together with the attributes of that assignment. A negated label
+// asserts that the label does not apply.
+type AppliedLabel struct {
+	Label
+	Attributes Attributes
+	Negated    bool
+}
+
+// AttributeDefinition declares the type of one attribute of a label.
+// Options lists the allowed values of enum attributes.
+type AttributeDefinition struct {
+	ID      int64
+	LabelID int64
+	Name    string
+	Type    string
+	Options []string
+}
+
+// AttributeDefinitionsForLabel returns the attribute schema of a label, ordered by name.
+func (d *DB) AttributeDefinitionsForLabel(labelID int64) ([]AttributeDefinition, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, label_id, name, type, options FROM label_attributes
+		 WHERE label_id = ?
+		 ORDER BY name ASC`,
+		labelID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching attribute definitions for label %d: %w", labelID, err)
+	}
+	defer rows.Close()
+
+	var defs []AttributeDefinition
+	for rows.Next() {
+		var def AttributeDefinition
+		var options string
+		if err := rows.Scan(&def.ID, &def.LabelID, &def.Name, &def.Type, &options); err != nil {
+			return nil, fmt.Errorf("scanning attribute definition: %w", err)
+		}
+		if err := json.Unmarshal([]byte(options), &def.Options); err != nil {
+			return nil, fmt.Errorf("decoding options of attribute %q: %w", def.Name, err)
+		}
+		defs = append(defs, def)
+	}
+	return defs, rows.Err()
+}
+
+// CreateAttributeDefinition adds an attribute to a label's schema.
+func (d *DB) CreateAttributeDefinition(labelID int64, name, attrType string, options []string) (*AttributeDefinition, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: attribute name is required", ErrInvalidAttributes)
+	}
+
+	var cleaned []string
+	for _, o := range options {
+		if o = strings.TrimSpace(o); o != "" && !slices.Contains(cleaned, o) {
+			cleaned = append(cleaned, o)
+		}
+	}
+
+	switch attrType {
+	case AttributeInt, AttributeFloat, AttributeString:
+		cleaned = nil
+	case AttributeEnum:
+		if len(cleaned) == 0 {
+			return nil, fmt.Errorf("%w: enum attribute %q needs at least one option", ErrInvalidAttributes, name)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown attribute type %q", ErrInvalidAttributes, attrType)
+	}
+
+	if cleaned == nil {
+		cleaned = []string{}
+	}
+	encoded, _ := json.Marshal(cleaned)
+
+	result, err := d.conn.Exec(
+		`INSERT INTO label_attributes (label_id, name, type, options) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (label_id, name) DO NOTHING`,
+		labelID, name, attrType, string(encoded),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating attribute %q for label %d: %w", name, labelID, err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("%w: attribute %q already exists", ErrInvalidAttributes, name)
+	}
+
+	id, _ := result.LastInsertId()
+	return &AttributeDefinition{ID: id, LabelID: labelID, Name: name, Type: attrType, Options: cleaned}, nil
+}
+
+// DeleteAttributeDefinition removes an attribute from a label's schema.
+// Values already stored on assignments are kept as free attributes.
+func (d *DB) DeleteAttributeDefinition(labelID, id int64) error {
+	_, err := d.conn.Exec(`DELETE FROM label_attributes WHERE id = ? AND label_id = ?`, id, labelID)
+	if err != nil {
+		return fmt.Errorf("deleting attribute %d of label %d: %w", id, labelID, err)
+	}
+	return nil
+}
+
+// ValidateAttributes checks attributes against a label's schema and returns them
+// with values converted to their declared types. Attributes not in the schema are
+// kept as free-form strings. Attributes with an empty value are dropped.
+func (d *DB) ValidateAttributes(labelID int64, attrs map[string]any) (Attributes, error) {
+	defs, err := d.AttributeDefinitionsForLabel(labelID)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := make(map[string]AttributeDefinition, len(defs))
+	for _, def := range defs {
+		schema[def.Name] = def
+	}
+
+	result := Attributes{}
+	for name, raw := range attrs {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: attribute name is required", ErrInvalidAttributes)
+		}
+		if raw == nil || raw == "" {
+			continue
+		}
+
+		def, ok := schema[name]
+		if !ok {
+			def = AttributeDefinition{Name: name, Type: AttributeString}
+		}
+
+		value, err := coerceValue(def.Type, def.Options, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidAttributes, name, err)
+		}
+		result[name] = value
+	}
+
+	return result, nil
+}
+
+// coerceValue converts a raw JSON value to the given type, rejecting values that don't fit.
+func coerceValue(valueType string, options []string, raw any) (any, error) {
+	switch valueType {
+	case AttributeInt:
+		f, err := toFloat(raw)
+		if err != nil || f != math.Trunc(f) {
+			return nil, errors.New("must be an integer")
+		}
+		return int64(f), nil
+	case AttributeFloat:
+		f, err := toFloat(raw)
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return f, nil
+	case AttributeEnum:
+		s := strings.TrimSpace(fmt.Sprint(raw))
+		if !slices.Contains(options, s) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+		}
+		return s, nil
+	case AttributeString:
+		switch v := raw.(type) {
+		case string:
+			return strings.TrimSpace(v), nil
+		case float64, bool:
+			return fmt.Sprint(v), nil
+		}
+		return nil, errors.New("must be a string")
+	}
+	return nil, fmt.Errorf("has unknown type %q", valueType)
+}
+
+// toFloat accepts a JSON number or a numeric string.
+func toFloat(raw any) (float64, error) {
+	switch v := raw.(type) {
+	case float64:
+		return v, nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(v), 64)
+	}
+	return 0, fmt.Errorf("unsupported value %v", raw)
+}
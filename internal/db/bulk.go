@@ -0,0 +1,173 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidBulkEdit is returned for a bulk edit that selects no files or
+// changes nothing.
+var ErrInvalidBulkEdit = errors.New("invalid bulk edit")
+
+// Selection chooses the files a bulk edit applies to: the files matching
+// the filter, narrowed to the given IDs when there are any. An empty
+// selection is rejected rather than taken to mean every file.
+type Selection struct {
+	IDs    []int64
+	Filter FileFilter
+}
+
+// IsEmpty reports whether the selection names neither files nor a filter.
+func (s Selection) IsEmpty() bool {
+	return len(s.IDs) == 0 && s.Filter.IsZero()
+}
+
+// Validate checks that the selection names files and its filter is valid.
+func (s Selection) Validate() error {
+	if s.IsEmpty() {
+		return fmt.Errorf("%w: select files by ID or filter", ErrInvalidBulkEdit)
+	}
+	return s.Filter.Validate()
+}
+
+// conditions returns the selection as SQL conditions on media_files
+// aliased as m, and their arguments.
+func (s Selection) conditions() (string, []any) {
+	where, args := s.Filter.conditions()
+	if len(s.IDs) > 0 {
+		where += ` AND m.id IN (?` + strings.Repeat(`, ?`, len(s.IDs)-1) + `)`
+		for _, id := range s.IDs {
+			args = append(args, id)
+		}
+	}
+	return where, args
+}
+
+// BulkEdit lists the changes made to every selected file. Empty fields
+// are left alone.
+type BulkEdit struct {
+	AddLabel          string // Added, or made positive where it's negated.
+	RemoveLabel       string
+	Status            string
+	AppendDescription string // Added as a new line after the existing description.
+}
+
+// IsZero reports whether the edit changes nothing.
+func (e BulkEdit) IsZero() bool {
+	return e == BulkEdit{}
+}
+
+// CountSelection returns how many files a selection covers, to preview a
+// bulk edit before applying it.
+func (d *DB) CountSelection(sel Selection) (int, error) {
+	if err := sel.Validate(); err != nil {
+		return 0, err
+	}
+
+	where, args := sel.conditions()
+	var count int
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM media_files m WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting selected media files: %w", err)
+	}
+	return count, nil
+}
+
+// ApplyBulkEdit makes the edit to every selected file in a single
+// transaction and returns how many files were selected.
+func (d *DB) ApplyBulkEdit(sel Selection, edit BulkEdit) (int, error) {
+	edit.AddLabel = strings.TrimSpace(edit.AddLabel)
+	edit.RemoveLabel = strings.TrimSpace(edit.RemoveLabel)
+	edit.AppendDescription = strings.TrimSpace(edit.AppendDescription)
+	if edit.IsZero() {
+		return 0, fmt.Errorf("%w: nothing to change", ErrInvalidBulkEdit)
+	}
+	if edit.Status != "" {
+		if err := ValidateStatus(edit.Status); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := sel.Validate(); err != nil {
+		return 0, err
+	}
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("beginning bulk edit: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Resolve the selection once, before any change can alter which files
+	// match its filter.
+	where, args := sel.conditions()
+	if _, err := tx.Exec(`DROP TABLE IF EXISTS temp.bulk_selection`); err != nil {
+		return 0, fmt.Errorf("clearing bulk selection: %w", err)
+	}
+	if _, err := tx.Exec(`CREATE TEMP TABLE bulk_selection AS SELECT m.id FROM media_files m WHERE `+where, args...); err != nil {
+		return 0, fmt.Errorf("selecting media files: %w", err)
+	}
+	var count int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM temp.bulk_selection`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting selected media files: %w", err)
+	}
+	selected := `SELECT id FROM temp.bulk_selection`
+
+	if edit.AddLabel != "" {
+		if _, err := tx.Exec(`INSERT INTO labels (name) VALUES (?) ON CONFLICT (name) DO NOTHING`, edit.AddLabel); err != nil {
+			return 0, fmt.Errorf("creating label %q: %w", edit.AddLabel, err)
+		}
+		_, err := tx.Exec(
+			`INSERT INTO media_labels (media_file_id, label_id, negated)
+			 SELECT id, (SELECT id FROM labels WHERE name = ?), 0 FROM temp.bulk_selection WHERE true
+			 ON CONFLICT DO UPDATE SET negated = 0`,
+			edit.AddLabel,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("adding label %q to selected media files: %w", edit.AddLabel, err)
+		}
+	}
+
+	if edit.RemoveLabel != "" {
+		_, err := tx.Exec(
+			`DELETE FROM media_labels
+			 WHERE label_id = (SELECT id FROM labels WHERE name = ?) AND media_file_id IN (`+selected+`)`,
+			edit.RemoveLabel,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("removing label %q from selected media files: %w", edit.RemoveLabel, err)
+		}
+	}
+
+	if edit.AppendDescription != "" {
+		_, err := tx.Exec(
+			`UPDATE media_files SET
+				description = CASE WHEN TRIM(description) = '' THEN ? ELSE description || char(10) || ? END,
+				updated_at = CURRENT_TIMESTAMP
+			 WHERE id IN (`+selected+`)`,
+			edit.AppendDescription, edit.AppendDescription,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("appending to descriptions of selected media files: %w", err)
+		}
+	}
+
+	if edit.Status != "" {
+		_, err := tx.Exec(
+			`UPDATE media_files SET
+				review = CASE WHEN (status = 'done') <> (? = 'done') THEN '' ELSE review END,
+				status = ?,
+				updated_at = CURRENT_TIMESTAMP
+			 WHERE id IN (`+selected+`)`,
+			edit.Status, edit.Status,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("updating status of selected media files: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DROP TABLE temp.bulk_selection`); err != nil {
+		return 0, fmt.Errorf("clearing bulk selection: %w", err)
+	}
+	return count, tx.Commit()
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCountSelection(t *testing.T) {
+	d := openTestDB(t)
+	ids := addFiles(t, d, "a.png", "b.png", "c.mp4", "d.mp4")
+	addLabel(t, d, ids["a.png"], "cat", false)
+	addLabel(t, d, ids["c.mp4"], "cat", false)
+
+	tests := []struct {
+		name    string
+		sel     Selection
+		want    int
+		wantErr error
+	}{
+		{"by ID", Selection{IDs: []int64{ids["a.png"], ids["b.png"]}}, 2, nil},
+		{"by filter", Selection{Filter: FileFilter{MediaType: "video"}}, 2, nil},
+		{"by query", Selection{Filter: FileFilter{Query: "label:cat"}}, 2, nil},
+		{"by ID within filter", Selection{IDs: []int64{ids["a.png"], ids["b.png"]}, Filter: FileFilter{Label: "cat"}}, 1, nil},
+		{"empty", Selection{}, 0, ErrInvalidBulkEdit},
+		{"invalid filter", Selection{Filter: FileFilter{Queue: "nope"}}, 0, ErrInvalidFilter},
+		{"invalid query", Selection{Filter: FileFilter{Query: "status:nope"}}, 0, ErrInvalidQuery},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.CountSelection(tt.sel)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CountSelection() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CountSelection() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyBulkEdit(t *testing.T) {
+	tests := []struct {
+		name string
+		edit BulkEdit
+		// check inspects the files after the edit, which selects a.png and b.png.
+		check func(t *testing.T, d *DB, ids map[string]int64)
+	}{
+		{
+			name: "add label",
+			edit: BulkEdit{AddLabel: " dog "},
+			check: func(t *testing.T, d *DB, ids map[string]int64) {
+				want := []string{"a.png", "b.png"}
+				if got := paths(t, d, FileFilter{Label: "dog"}); !slices.Equal(got, want) {
+					t.Errorf("files labeled dog = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "add negated label",
+			edit: BulkEdit{AddLabel: "cat"},
+			check: func(t *testing.T, d *DB, ids map[string]int64) {
+				if got := paths(t, d, FileFilter{NoLabel: "cat"}); len(got) != 0 {
+					t.Errorf("files marked without cat = %v, want none", got)
+				}
+			},
+		},
+		{
+			name: "remove label",
+			edit: BulkEdit{RemoveLabel: "cat"},
+			check: func(t *testing.T, d *DB, ids map[string]int64) {
+				want := []string{"c.png"}
+				if got := paths(t, d, FileFilter{Query: "label:cat"}); !slices.Equal(got, want) {
+					t.Errorf("files labeled cat = %v, want %v", got, want)
+				}
+			},
+		},
+		{
+			name: "append description",
+			edit: BulkEdit{AppendDescription: "outdoors"},
+			check: func(t *testing.T, d *DB, ids map[string]int64) {
+				for path, want := range map[string]string{"a.png": "outdoors", "b.png": "a cat\noutdoors", "c.png": ""} {
+					m, _ := d.GetMediaFile(ids[path])
+					if m.Description != want {
+						t.Errorf("description of %s = %q, want %q", path, m.Description, want)
+					}
+				}
+			},
+		},
+		{
+			name: "status clears review",
+			edit: BulkEdit{Status: StatusTodo},
+			check: func(t *testing.T, d *DB, ids map[string]int64) {
+				m, _ := d.GetMediaFile(ids["b.png"])
+				if m.Status != StatusTodo || m.Review != "" {
+					t.Errorf("b.png has status %q and review %q, want todo and no review", m.Status, m.Review)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := openTestDB(t)
+			ids := addFiles(t, d, "a.png", "b.png", "c.png")
+			addLabel(t, d, ids["a.png"], "cat", true)
+			addLabel(t, d, ids["b.png"], "cat", false)
+			addLabel(t, d, ids["c.png"], "cat", false)
+			if err := d.UpdateDescription(ids["b.png"], "a cat"); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.SetStatus(ids["b.png"], StatusDone); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := d.ReviewMediaFile(ids["b.png"], "rita", ReviewApproved, ""); err != nil {
+				t.Fatal(err)
+			}
+
+			sel := Selection{Filter: FileFilter{Path: "[ab].png"}}
+			count, err := d.ApplyBulkEdit(sel, tt.edit)
+			if err != nil {
+				t.Fatalf("ApplyBulkEdit() error = %v", err)
+			}
+			if count != 2 {
+				t.Errorf("ApplyBulkEdit() = %d, want 2", count)
+			}
+			tt.check(t, d, ids)
+		})
+	}
+}
+
+func TestApplyBulkEditResolvesSelectionFirst(t *testing.T) {
+	d := openTestDB(t)
+	ids := addFiles(t, d, "a.png", "b.png", "c.png")
+	addLabel(t, d, ids["c.png"], "cat", false)
+
+	// Adding the label takes the files out of the unlabeled queue, but the
+	// description is still appended to all of them.
+	sel := Selection{Filter: FileFilter{Queue: QueueUnlabeled}}
+	count, err := d.ApplyBulkEdit(sel, BulkEdit{AddLabel: "dog", AppendDescription: "checked"})
+	if err != nil {
+		t.Fatalf("ApplyBulkEdit() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("ApplyBulkEdit() = %d, want 2", count)
+	}
+	want := []string{"a.png", "b.png"}
+	if got := paths(t, d, FileFilter{Description: "checked"}); !slices.Equal(got, want) {
+		t.Errorf("described files = %v, want %v", got, want)
+	}
+}
+
+func TestApplyBulkEditErrors(t *testing.T) {
+	d := openTestDB(t)
+	ids := addFiles(t, d, "a.png")
+	sel := Selection{IDs: []int64{ids["a.png"]}}
+
+	tests := []struct {
+		name    string
+		sel     Selection
+		edit    BulkEdit
+		wantErr error
+	}{
+		{"nothing to change", sel, BulkEdit{AddLabel: "  "}, ErrInvalidBulkEdit},
+		{"unknown status", sel, BulkEdit{Status: "nope"}, ErrInvalidStatus},
+		{"no selection", Selection{}, BulkEdit{AddLabel: "dog"}, ErrInvalidBulkEdit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := d.ApplyBulkEdit(tt.sel, tt.edit); !errors.Is(err, tt.wantErr) {
+				t.Errorf("ApplyBulkEdit() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
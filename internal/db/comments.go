@@ -0,0 +1,247 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidComment is returned for an empty comment or one on a keyframe
+// of another file.
+var ErrInvalidComment = errors.New("invalid comment")
+
+// CommentThread is a discussion about a media file, or one of its keyframes
+// when KeyframeID is set. Comments are review notes kept apart from the
+// annotations, so they never appear in exports.
+type CommentThread struct {
+	ID          int64
+	MediaFileID int64
+	KeyframeID  int64 // 0 for threads about the whole file.
+	TimestampMs int64 // Position of the keyframe, if any.
+	Path        string
+	Resolved    bool
+	ResolvedBy  string
+	ResolvedAt  time.Time
+	CreatedAt   time.Time
+	Comments    []Comment // Oldest first; the first one opened the thread.
+}
+
+// Comment is a single message in a comment thread.
+type Comment struct {
+	ID        int64
+	ThreadID  int64
+	Author    string
+	Body      string
+	CreatedAt time.Time
+}
+
+// commentThreadColumns lists the columns read by scanCommentThread, in order.
+const commentThreadColumns = `t.id, t.media_file_id, COALESCE(t.keyframe_id, 0), COALESCE(k.timestamp_ms, 0),
+	m.path, t.resolved_by, t.resolved_at, t.created_at`
+
+// commentThreadTables joins the tables commentThreadColumns reads from.
+const commentThreadTables = `comment_threads t
+	JOIN media_files m ON m.id = t.media_file_id
+	LEFT JOIN keyframes k ON k.id = t.keyframe_id`
+
+func scanCommentThread(row rowScanner, t *CommentThread) error {
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&t.ID, &t.MediaFileID, &t.KeyframeID, &t.TimestampMs, &t.Path, &t.ResolvedBy, &resolvedAt, &t.CreatedAt); err != nil {
+		return err
+	}
+	t.Resolved = resolvedAt.Valid
+	t.ResolvedAt = resolvedAt.Time
+	return nil
+}
+
+// CommentThreadsForMediaFile returns every thread about a file or its
+// keyframes, open ones first, each with its comments.
+func (d *DB) CommentThreadsForMediaFile(mediaFileID int64) ([]CommentThread, error) {
+	return d.commentThreads(
+		`t.media_file_id = ?`,
+		`t.resolved_at IS NOT NULL, t.created_at ASC, t.id ASC`,
+		mediaFileID,
+	)
+}
+
+// OpenCommentThreads returns every unresolved thread in the project, the
+// most recently started first, each with its comments.
+func (d *DB) OpenCommentThreads() ([]CommentThread, error) {
+	return d.commentThreads(`t.resolved_at IS NULL`, `t.created_at DESC, t.id DESC`)
+}
+
+// commentThreads returns the threads matching a condition on comment_threads
+// aliased as t, in the given order.
+func (d *DB) commentThreads(where, order string, args ...any) ([]CommentThread, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+commentThreadColumns+` FROM `+commentThreadTables+` WHERE `+where+` ORDER BY `+order,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching comment threads: %w", err)
+	}
+	defer rows.Close()
+
+	var threads []CommentThread
+	for rows.Next() {
+		var t CommentThread
+		if err := scanCommentThread(rows, &t); err != nil {
+			return nil, fmt.Errorf("scanning comment thread: %w", err)
+		}
+		threads = append(threads, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range threads {
+		comments, err := d.CommentsForThread(threads[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		threads[i].Comments = comments
+	}
+
+	return threads, nil
+}
+
+// GetCommentThread returns a single thread with its comments, or nil if it
+// doesn't exist.
+func (d *DB) GetCommentThread(id int64) (*CommentThread, error) {
+	t := &CommentThread{}
+	row := d.conn.QueryRow(`SELECT `+commentThreadColumns+` FROM `+commentThreadTables+` WHERE t.id = ?`, id)
+	err := scanCommentThread(row, t)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching comment thread %d: %w", id, err)
+	}
+
+	if t.Comments, err = d.CommentsForThread(t.ID); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// CommentsForThread returns the comments of a thread, oldest first.
+func (d *DB) CommentsForThread(threadID int64) ([]Comment, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, thread_id, author, body, created_at FROM comments
+		 WHERE thread_id = ? ORDER BY created_at ASC, id ASC`,
+		threadID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching comments of thread %d: %w", threadID, err)
+	}
+	defer rows.Close()
+
+	var comments []Comment
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.ThreadID, &c.Author, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// CreateCommentThread starts a thread about a media file, or one of its
+// keyframes when keyframeID isn't 0, with a first comment.
+func (d *DB) CreateCommentThread(mediaFileID, keyframeID int64, author, body string) (*CommentThread, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: comment is empty", ErrInvalidComment)
+	}
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning comment thread: %w", err)
+	}
+	defer tx.Rollback()
+
+	var keyframe any
+	if keyframeID != 0 {
+		var owner int64
+		err := tx.QueryRow(`SELECT media_file_id FROM keyframes WHERE id = ?`, keyframeID).Scan(&owner)
+		if err == sql.ErrNoRows || owner != mediaFileID {
+			return nil, fmt.Errorf("%w: keyframe %d isn't part of media file %d", ErrInvalidComment, keyframeID, mediaFileID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fetching keyframe %d: %w", keyframeID, err)
+		}
+		keyframe = keyframeID
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO comment_threads (media_file_id, keyframe_id) VALUES (?, ?)`,
+		mediaFileID, keyframe,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating comment thread for media file %d: %w", mediaFileID, err)
+	}
+	id, _ := result.LastInsertId()
+
+	if _, err := tx.Exec(`INSERT INTO comments (thread_id, author, body) VALUES (?, ?, ?)`, id, author, body); err != nil {
+		return nil, fmt.Errorf("adding comment to thread %d: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return d.GetCommentThread(id)
+}
+
+// AddComment replies to a thread. Replying doesn't reopen a resolved thread.
+func (d *DB) AddComment(threadID int64, author, body string) (*Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: comment is empty", ErrInvalidComment)
+	}
+
+	result, err := d.conn.Exec(
+		`INSERT INTO comments (thread_id, author, body) VALUES (?, ?, ?)`,
+		threadID, author, body,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("adding comment to thread %d: %w", threadID, err)
+	}
+
+	id, _ := result.LastInsertId()
+	c := &Comment{}
+	err = d.conn.QueryRow(
+		`SELECT id, thread_id, author, body, created_at FROM comments WHERE id = ?`, id,
+	).Scan(&c.ID, &c.ThreadID, &c.Author, &c.Body, &c.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("fetching comment %d: %w", id, err)
+	}
+	return c, nil
+}
+
+// SetCommentThreadResolved resolves a thread on behalf of user, or reopens it.
+func (d *DB) SetCommentThreadResolved(id int64, resolved bool, user string) error {
+	var result sql.Result
+	var err error
+	if resolved {
+		result, err = d.conn.Exec(
+			`UPDATE comment_threads SET resolved_by = ?, resolved_at = CURRENT_TIMESTAMP WHERE id = ?`,
+			user, id,
+		)
+	} else {
+		result, err = d.conn.Exec(
+			`UPDATE comment_threads SET resolved_by = '', resolved_at = NULL WHERE id = ?`,
+			id,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("updating comment thread %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("comment thread %d not found", id)
+	}
+	return nil
+}
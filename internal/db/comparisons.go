@@ -0,0 +1,200 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// ErrInvalidComparison is returned when a judgment doesn't name a valid pair or winner.
+var ErrInvalidComparison = errors.New("invalid comparison")
+
+// Outcomes of a comparison.
+const (
+	WinnerLeft  = "left"
+	WinnerRight = "right"
+	WinnerTie   = "tie"
+)
+
+// Comparison is one pairwise judgment of which of two media files is better.
+type Comparison struct {
+	ID          int64
+	LeftFileID  int64
+	LeftPath    string
+	RightFileID int64
+	RightPath   string
+	Winner      string // WinnerLeft, WinnerRight or WinnerTie.
+	Reason      string
+	Annotator   string
+	CreatedAt   time.Time
+}
+
+// PairFilter restricts which files are sampled for comparison. Empty
+// fields don't restrict anything.
+type PairFilter struct {
+	Label     string // Only files carrying this label.
+	Directory string // Only files within this directory, relative to the project root.
+}
+
+// pairCandidate is a file that may be sampled for comparison.
+type pairCandidate struct {
+	id        int64
+	mediaType string
+}
+
+// SamplePair picks two files of the same media type that the annotator
+// hasn't compared yet, preferring files with the fewest judgments so
+// comparisons spread over the whole set. The order of the two is random.
+// It returns nil files when every matching pair has been compared.
+func (d *DB) SamplePair(filter PairFilter, annotator string) (*MediaFile, *MediaFile, error) {
+	files := FileFilter{Label: filter.Label}
+	if dir := strings.Trim(filter.Directory, "/"); dir != "" && dir != "." {
+		files.Path = dir + "/"
+	}
+	where, args := files.conditions()
+	query := `SELECT m.id, m.media_type FROM media_files m WHERE ` + where
+	query += ` ORDER BY (SELECT COUNT(*) FROM comparisons c
+		WHERE c.left_file_id = m.id OR c.right_file_id = m.id) ASC, RANDOM()`
+
+	rows, err := d.conn.Query(query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sampling files to compare: %w", err)
+	}
+	var candidates []pairCandidate
+	for rows.Next() {
+		var c pairCandidate
+		if err := rows.Scan(&c.id, &c.mediaType); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("scanning file to compare: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	compared, err := d.comparedPairs(annotator)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, a := range candidates {
+		for _, b := range candidates[i+1:] {
+			if a.mediaType != b.mediaType || compared[pairKey(a.id, b.id)] {
+				continue
+			}
+			if rand.IntN(2) == 0 {
+				a, b = b, a
+			}
+			left, err := d.GetMediaFile(a.id)
+			if err != nil {
+				return nil, nil, err
+			}
+			right, err := d.GetMediaFile(b.id)
+			if err != nil {
+				return nil, nil, err
+			}
+			return left, right, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// comparedPairs returns the pairs the annotator has already judged, keyed by pairKey.
+func (d *DB) comparedPairs(annotator string) (map[[2]int64]bool, error) {
+	rows, err := d.conn.Query(
+		`SELECT left_file_id, right_file_id FROM comparisons WHERE annotator = ?`,
+		annotator,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching comparisons by %q: %w", annotator, err)
+	}
+	defer rows.Close()
+
+	compared := make(map[[2]int64]bool)
+	for rows.Next() {
+		var a, b int64
+		if err := rows.Scan(&a, &b); err != nil {
+			return nil, fmt.Errorf("scanning comparison: %w", err)
+		}
+		compared[pairKey(a, b)] = true
+	}
+	return compared, rows.Err()
+}
+
+// pairKey identifies a pair of files regardless of their order.
+func pairKey(a, b int64) [2]int64 {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int64{a, b}
+}
+
+// CreateComparison records a judgment of two files by an annotator.
+func (d *DB) CreateComparison(leftFileID, rightFileID int64, winner, reason, annotator string) (*Comparison, error) {
+	if winner != WinnerLeft && winner != WinnerRight && winner != WinnerTie {
+		return nil, fmt.Errorf("%w: winner must be left, right or tie", ErrInvalidComparison)
+	}
+	if leftFileID == rightFileID {
+		return nil, fmt.Errorf("%w: a file can't be compared with itself", ErrInvalidComparison)
+	}
+
+	result, err := d.conn.Exec(
+		`INSERT INTO comparisons (left_file_id, right_file_id, winner, reason, annotator) VALUES (?, ?, ?, ?, ?)`,
+		leftFileID, rightFileID, winner, strings.TrimSpace(reason), annotator,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating comparison of media files %d and %d: %w", leftFileID, rightFileID, err)
+	}
+
+	id, _ := result.LastInsertId()
+	comparisons, err := d.queryComparisons(`WHERE c.id = ?`, id)
+	if err != nil || len(comparisons) == 0 {
+		return nil, err
+	}
+	return &comparisons[0], nil
+}
+
+// AllComparisons returns every judgment in the order they were made.
+func (d *DB) AllComparisons() ([]Comparison, error) {
+	return d.queryComparisons(``)
+}
+
+// ComparisonCount returns how many judgments an annotator has made.
+func (d *DB) ComparisonCount(annotator string) (int, error) {
+	var count int
+	err := d.conn.QueryRow(`SELECT COUNT(*) FROM comparisons WHERE annotator = ?`, annotator).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("counting comparisons by %q: %w", annotator, err)
+	}
+	return count, nil
+}
+
+func (d *DB) queryComparisons(where string, args ...any) ([]Comparison, error) {
+	rows, err := d.conn.Query(
+		`SELECT c.id, c.left_file_id, lm.path, c.right_file_id, rm.path, c.winner, c.reason, c.annotator, c.created_at
+		 FROM comparisons c
+		 JOIN media_files lm ON lm.id = c.left_file_id
+		 JOIN media_files rm ON rm.id = c.right_file_id
+		 `+where+` ORDER BY c.id ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching comparisons: %w", err)
+	}
+	defer rows.Close()
+
+	var comparisons []Comparison
+	for rows.Next() {
+		var c Comparison
+		if err := rows.Scan(&c.ID, &c.LeftFileID, &c.LeftPath, &c.RightFileID, &c.RightPath,
+			&c.Winner, &c.Reason, &c.Annotator, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning comparison: %w", err)
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons, rows.Err()
+}
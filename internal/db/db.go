@@ -7,7 +7,7 @@ import (
 	_ "modernc.org/sqlite"
 )
 
-const currentVersion = 1
+const currentVersion = 21
 
 // DB wraps a SQLite database connection.
 type DB struct {
@@ -69,6 +69,126 @@ func (d *DB) migrate() error {
 		}
 	}
 
+	if version < 2 {
+		if err := migrateV2(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 3 {
+		if err := migrateV3(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 4 {
+		if err := migrateV4(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 5 {
+		if err := migrateV5(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 6 {
+		if err := migrateV6(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 7 {
+		if err := migrateV7(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 8 {
+		if err := migrateV8(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 9 {
+		if err := migrateV9(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 10 {
+		if err := migrateV10(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 11 {
+		if err := migrateV11(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 12 {
+		if err := migrateV12(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 13 {
+		if err := migrateV13(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 14 {
+		if err := migrateV14(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 15 {
+		if err := migrateV15(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 16 {
+		if err := migrateV16(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 17 {
+		if err := migrateV17(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 18 {
+		if err := migrateV18(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 19 {
+		if err := migrateV19(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 20 {
+		if err := migrateV20(tx); err != nil {
+			return err
+		}
+	}
+
+	if version < 21 {
+		if err := migrateV21(tx); err != nil {
+			return err
+		}
+	}
+
 	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", currentVersion)); err != nil {
 		return fmt.Errorf("updating schema version: %w", err)
 	}
@@ -117,3 +237,523 @@ func migrateV1(tx *sql.Tx) error {
 
 	return nil
 }
+
+// migrateV2 adds annotation guidelines to labels: a long-form definition,
+// positive and negative example notes, and media files used as visual examples.
+func migrateV2(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE labels ADD COLUMN definition TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE labels ADD COLUMN positive_notes TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE labels ADD COLUMN negative_notes TEXT NOT NULL DEFAULT ''`,
+		`CREATE TABLE label_examples (
+			label_id INTEGER NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+			media_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			PRIMARY KEY (label_id, media_file_id)
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v2: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV3 adds per-assignment attributes to media and keyframe labels,
+// and an optional per-label attribute schema.
+func migrateV3(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE media_labels ADD COLUMN attributes TEXT NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE keyframe_labels ADD COLUMN attributes TEXT NOT NULL DEFAULT '{}'`,
+		`CREATE TABLE label_attributes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			label_id INTEGER NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			type TEXT NOT NULL,
+			options TEXT NOT NULL DEFAULT '[]',
+			UNIQUE (label_id, name)
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v3: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV4 adds a polarity to label assignments, so a label can be
+// asserted as absent rather than merely missing.
+func migrateV4(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE media_labels ADD COLUMN negated INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE keyframe_labels ADD COLUMN negated INTEGER NOT NULL DEFAULT 0`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v4: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV5 adds regions: rectangles on an image, in coordinates normalized
+// to the image size, with their own labels and description.
+func migrateV5(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE regions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			media_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			x REAL NOT NULL,
+			y REAL NOT NULL,
+			width REAL NOT NULL,
+			height REAL NOT NULL,
+			description TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE TABLE region_labels (
+			region_id INTEGER NOT NULL REFERENCES regions(id) ON DELETE CASCADE,
+			label_id INTEGER NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+			attributes TEXT NOT NULL DEFAULT '{}',
+			negated INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (region_id, label_id)
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v5: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV6 adds polygon and mask regions, and stores the pixel size of
+// images so that their vertices can be snapped to the pixel grid. Every
+// region keeps its bounding box in x, y, width and height.
+func migrateV6(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE regions ADD COLUMN kind TEXT NOT NULL DEFAULT 'box'`,
+		`ALTER TABLE regions ADD COLUMN points TEXT NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE regions ADD COLUMN mask TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE media_files ADD COLUMN width INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE media_files ADD COLUMN height INTEGER NOT NULL DEFAULT 0`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v6: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV7 adds skeleton templates to labels and keypoint regions, which
+// place one instance of a label's skeleton on an image.
+func migrateV7(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE label_skeletons (
+			label_id INTEGER PRIMARY KEY REFERENCES labels(id) ON DELETE CASCADE,
+			keypoints TEXT NOT NULL DEFAULT '[]',
+			edges TEXT NOT NULL DEFAULT '[]'
+		)`,
+		`ALTER TABLE regions ADD COLUMN skeleton_label_id INTEGER REFERENCES labels(id) ON DELETE SET NULL`,
+		`ALTER TABLE regions ADD COLUMN keypoints TEXT NOT NULL DEFAULT '[]'`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v7: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV8 adds segments: time ranges on a video or audio file with their
+// own labels and description. Segments may overlap.
+func migrateV8(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE segments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			media_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			start_ms INTEGER NOT NULL,
+			end_ms INTEGER NOT NULL,
+			description TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE TABLE segment_labels (
+			segment_id INTEGER NOT NULL REFERENCES segments(id) ON DELETE CASCADE,
+			label_id INTEGER NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+			attributes TEXT NOT NULL DEFAULT '{}',
+			negated INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (segment_id, label_id)
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v8: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV9 adds object tracks on videos: a labeled identity whose boxes are
+// set at some timestamps and interpolated in between. Videos also get their
+// pixel size and duration, as reported by the browser.
+func migrateV9(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE media_files ADD COLUMN duration_ms INTEGER NOT NULL DEFAULT 0`,
+		`CREATE TABLE tracks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			media_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			label_id INTEGER NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+			name TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE TABLE track_boxes (
+			track_id INTEGER NOT NULL REFERENCES tracks(id) ON DELETE CASCADE,
+			timestamp_ms INTEGER NOT NULL,
+			x REAL NOT NULL,
+			y REAL NOT NULL,
+			width REAL NOT NULL,
+			height REAL NOT NULL,
+			PRIMARY KEY (track_id, timestamp_ms)
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v9: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV10 adds a verbatim transcript and its language to keyframes and
+// segments, kept apart from the annotator's description.
+func migrateV10(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE keyframes ADD COLUMN transcript TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE keyframes ADD COLUMN language TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE segments ADD COLUMN transcript TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE segments ADD COLUMN language TEXT NOT NULL DEFAULT ''`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v10: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV11 adds the values of project-defined form fields. Each value is
+// stored in the column of its type, so it can be compared as that type.
+func migrateV11(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE field_values (
+			media_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			type TEXT NOT NULL,
+			int_value INTEGER,
+			real_value REAL,
+			text_value TEXT,
+			PRIMARY KEY (media_file_id, name)
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v11: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func migrateV12(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE comparisons (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			left_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			right_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			winner TEXT NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			annotator TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v12: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV13 adds spans: labeled character ranges of text files, stored like
+// segments but with offsets instead of timestamps.
+func migrateV13(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE spans (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			media_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			start_offset INTEGER NOT NULL,
+			end_offset INTEGER NOT NULL,
+			description TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE TABLE span_labels (
+			span_id INTEGER NOT NULL REFERENCES spans(id) ON DELETE CASCADE,
+			label_id INTEGER NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+			attributes TEXT NOT NULL DEFAULT '{}',
+			negated INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (span_id, label_id)
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v13: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV14 adds named descriptions, kept next to the main description of
+// a file in the slots the project declares.
+func migrateV14(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE media_descriptions (
+			media_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			name TEXT NOT NULL,
+			description TEXT NOT NULL,
+			PRIMARY KEY (media_file_id, name)
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v14: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV15 adds the workflow status of media files.
+func migrateV15(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE media_files ADD COLUMN status TEXT NOT NULL DEFAULT 'todo'`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v15: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV16 adds full-text indexes over file and keyframe descriptions.
+// They're external content tables, so the triggers must remove exactly
+// the text that was indexed before indexing the new one.
+func migrateV16(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE VIRTUAL TABLE media_files_fts USING fts5(
+			description,
+			content = 'media_files', content_rowid = 'id',
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`,
+		`CREATE TRIGGER media_files_fts_insert AFTER INSERT ON media_files BEGIN
+			INSERT INTO media_files_fts (rowid, description) VALUES (new.id, new.description);
+		END`,
+		`CREATE TRIGGER media_files_fts_delete AFTER DELETE ON media_files BEGIN
+			INSERT INTO media_files_fts (media_files_fts, rowid, description) VALUES ('delete', old.id, old.description);
+		END`,
+		`CREATE TRIGGER media_files_fts_update AFTER UPDATE OF description ON media_files BEGIN
+			INSERT INTO media_files_fts (media_files_fts, rowid, description) VALUES ('delete', old.id, old.description);
+			INSERT INTO media_files_fts (rowid, description) VALUES (new.id, new.description);
+		END`,
+		`INSERT INTO media_files_fts (media_files_fts) VALUES ('rebuild')`,
+
+		`CREATE VIRTUAL TABLE keyframes_fts USING fts5(
+			description,
+			content = 'keyframes', content_rowid = 'id',
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`,
+		`CREATE TRIGGER keyframes_fts_insert AFTER INSERT ON keyframes BEGIN
+			INSERT INTO keyframes_fts (rowid, description) VALUES (new.id, new.description);
+		END`,
+		`CREATE TRIGGER keyframes_fts_delete AFTER DELETE ON keyframes BEGIN
+			INSERT INTO keyframes_fts (keyframes_fts, rowid, description) VALUES ('delete', old.id, old.description);
+		END`,
+		`CREATE TRIGGER keyframes_fts_update AFTER UPDATE OF description ON keyframes BEGIN
+			INSERT INTO keyframes_fts (keyframes_fts, rowid, description) VALUES ('delete', old.id, old.description);
+			INSERT INTO keyframes_fts (rowid, description) VALUES (new.id, new.description);
+		END`,
+		`INSERT INTO keyframes_fts (keyframes_fts) VALUES ('rebuild')`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v16: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV17 adds comment threads on files and keyframes, for review notes
+// that mustn't end up in descriptions or exports.
+func migrateV17(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE comment_threads (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			media_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			keyframe_id INTEGER REFERENCES keyframes(id) ON DELETE CASCADE,
+			resolved_by TEXT NOT NULL DEFAULT '',
+			resolved_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE comments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			thread_id INTEGER NOT NULL REFERENCES comment_threads(id) ON DELETE CASCADE,
+			author TEXT NOT NULL DEFAULT '',
+			body TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v17: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV18 adds reviews: the latest decision on each file, and a log of
+// who approved or rejected what and when.
+func migrateV18(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE media_files ADD COLUMN review TEXT NOT NULL DEFAULT ''`,
+		`CREATE TABLE reviews (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			media_file_id INTEGER NOT NULL REFERENCES media_files(id) ON DELETE CASCADE,
+			reviewer TEXT NOT NULL,
+			decision TEXT NOT NULL,
+			reason TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v18: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV19 adds the size and modification time of files, read when the
+// directory is scanned, and per-project settings such as the navigation
+// order.
+func migrateV19(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE media_files ADD COLUMN size INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE media_files ADD COLUMN modified_ms INTEGER NOT NULL DEFAULT 0`,
+		`CREATE TABLE settings (
+			name TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v19: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV20 adds each file's position in the navigation order, ranked
+// when the order is set so it doesn't change while files are labeled.
+func migrateV20(tx *sql.Tx) error {
+	statements := []string{
+		`ALTER TABLE media_files ADD COLUMN position INTEGER`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v20: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV21 adds full-text indexes over description slots and segment
+// descriptions. Slots have no ID column to serve as a stable rowid, so
+// their index keeps its own copy of the text, keyed by file and slot.
+func migrateV21(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE VIRTUAL TABLE media_descriptions_fts USING fts5(
+			media_file_id UNINDEXED, name UNINDEXED, description,
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`,
+		`CREATE TRIGGER media_descriptions_fts_insert AFTER INSERT ON media_descriptions BEGIN
+			INSERT INTO media_descriptions_fts (media_file_id, name, description) VALUES (new.media_file_id, new.name, new.description);
+		END`,
+		`CREATE TRIGGER media_descriptions_fts_delete AFTER DELETE ON media_descriptions BEGIN
+			DELETE FROM media_descriptions_fts WHERE media_file_id = old.media_file_id AND name = old.name;
+		END`,
+		`CREATE TRIGGER media_descriptions_fts_update AFTER UPDATE ON media_descriptions BEGIN
+			DELETE FROM media_descriptions_fts WHERE media_file_id = old.media_file_id AND name = old.name;
+			INSERT INTO media_descriptions_fts (media_file_id, name, description) VALUES (new.media_file_id, new.name, new.description);
+		END`,
+		`INSERT INTO media_descriptions_fts (media_file_id, name, description)
+			SELECT media_file_id, name, description FROM media_descriptions`,
+
+		`CREATE VIRTUAL TABLE segments_fts USING fts5(
+			description,
+			content = 'segments', content_rowid = 'id',
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`,
+		`CREATE TRIGGER segments_fts_insert AFTER INSERT ON segments BEGIN
+			INSERT INTO segments_fts (rowid, description) VALUES (new.id, new.description);
+		END`,
+		`CREATE TRIGGER segments_fts_delete AFTER DELETE ON segments BEGIN
+			INSERT INTO segments_fts (segments_fts, rowid, description) VALUES ('delete', old.id, old.description);
+		END`,
+		`CREATE TRIGGER segments_fts_update AFTER UPDATE OF description ON segments BEGIN
+			INSERT INTO segments_fts (segments_fts, rowid, description) VALUES ('delete', old.id, old.description);
+			INSERT INTO segments_fts (rowid, description) VALUES (new.id, new.description);
+		END`,
+		`INSERT INTO segments_fts (segments_fts) VALUES ('rebuild')`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration v21: %w", err)
+		}
+	}
+
+	return nil
+}
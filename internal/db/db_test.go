@@ -0,0 +1,73 @@
+package db
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+// openTestDB opens a migrated in-memory database, closed when the test ends.
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	d, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return d
+}
+
+// testMediaTypes maps the extensions used in tests to media types.
+var testMediaTypes = map[string]string{
+	".png": "image",
+	".mp4": "video",
+	".mp3": "audio",
+	".txt": "text",
+}
+
+// addFiles adds media files by path, ranks them in path order, and
+// returns their IDs by path.
+func addFiles(t *testing.T, d *DB, paths ...string) map[string]int64 {
+	t.Helper()
+	ids := make(map[string]int64)
+	for _, p := range paths {
+		if err := d.UpsertMediaFile(p, testMediaTypes[path.Ext(p)], 0, time.Time{}); err != nil {
+			t.Fatalf("adding %s: %v", p, err)
+		}
+		m, err := d.GetMediaFileByPath(p)
+		if err != nil || m == nil {
+			t.Fatalf("fetching %s: %v", p, err)
+		}
+		ids[p] = m.ID
+	}
+	if err := d.RefreshNavigationOrder(); err != nil {
+		t.Fatalf("ranking files: %v", err)
+	}
+	return ids
+}
+
+// addLabel applies a label, by name, to a media file.
+func addLabel(t *testing.T, d *DB, fileID int64, name string, negated bool) {
+	t.Helper()
+	label, err := d.FindOrCreateLabel(name)
+	if err != nil {
+		t.Fatalf("creating label %q: %v", name, err)
+	}
+	if err := d.AddAppliedLabel(MediaFileLabels, fileID, label.ID, negated); err != nil {
+		t.Fatalf("labeling media file %d %q: %v", fileID, name, err)
+	}
+}
+
+// paths returns the paths of the media files matching a filter, sorted.
+func paths(t *testing.T, d *DB, filter FileFilter) []string {
+	t.Helper()
+	files, err := d.MediaFiles(filter)
+	if err != nil {
+		t.Fatalf("fetching media files: %v", err)
+	}
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	return paths
+}
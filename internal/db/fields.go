@@ -0,0 +1,168 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidField is returned when a form field value doesn't match its definition.
+var ErrInvalidField = errors.New("invalid field")
+
+// FieldBool is the field type of checkboxes. The other field types are
+// shared with label attributes.
+const FieldBool = "bool"
+
+// FieldDefinition declares a form field that every media file of a project
+// can fill in, e.g. num_people (int) or lighting (enum). Options lists the
+// allowed values of enum fields.
+type FieldDefinition struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Options []string `json:"options,omitempty"`
+}
+
+// Validate checks that the definition has a name and a known type.
+func (f FieldDefinition) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return fmt.Errorf("%w: field name is required", ErrInvalidField)
+	}
+	switch f.Type {
+	case AttributeInt, AttributeFloat, AttributeString, FieldBool:
+	case AttributeEnum:
+		if len(f.Options) == 0 {
+			return fmt.Errorf("%w: enum field %q needs at least one option", ErrInvalidField, f.Name)
+		}
+	default:
+		return fmt.Errorf("%w: field %q has unknown type %q", ErrInvalidField, f.Name, f.Type)
+	}
+	return nil
+}
+
+// Coerce converts a raw JSON value to the field's type. An empty value
+// clears the field and is returned as nil.
+func (f FieldDefinition) Coerce(raw any) (any, error) {
+	if s, ok := raw.(string); raw == nil || ok && strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	if f.Type == FieldBool {
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.TrimSpace(v) {
+			case "true":
+				return true, nil
+			case "false":
+				return false, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s must be true or false", ErrInvalidField, f.Name)
+	}
+
+	value, err := coerceValue(f.Type, f.Options, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %v", ErrInvalidField, f.Name, err)
+	}
+	return value, nil
+}
+
+// fieldValueColumn returns the field_values column holding values of a
+// field type.
+func fieldValueColumn(fieldType string) string {
+	switch fieldType {
+	case AttributeInt, FieldBool:
+		return "int_value"
+	case AttributeFloat:
+		return "real_value"
+	}
+	return "text_value"
+}
+
+// FieldValues maps field names to their typed values: int64, float64, bool or string.
+type FieldValues map[string]any
+
+// FieldValuesForMediaFile returns the field values of a media file.
+func (d *DB) FieldValuesForMediaFile(mediaFileID int64) (FieldValues, error) {
+	rows, err := d.conn.Query(
+		`SELECT name, type, int_value, real_value, text_value FROM field_values
+		 WHERE media_file_id = ?`,
+		mediaFileID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching fields of media file %d: %w", mediaFileID, err)
+	}
+	defer rows.Close()
+
+	values := FieldValues{}
+	for rows.Next() {
+		var name, fieldType string
+		var intValue sql.NullInt64
+		var realValue sql.NullFloat64
+		var textValue sql.NullString
+		if err := rows.Scan(&name, &fieldType, &intValue, &realValue, &textValue); err != nil {
+			return nil, fmt.Errorf("scanning field value: %w", err)
+		}
+
+		switch fieldType {
+		case AttributeInt:
+			values[name] = intValue.Int64
+		case FieldBool:
+			values[name] = intValue.Int64 != 0
+		case AttributeFloat:
+			values[name] = realValue.Float64
+		default:
+			values[name] = textValue.String
+		}
+	}
+	return values, rows.Err()
+}
+
+// SetFieldValue validates and stores the value of a field on a media file,
+// returning the stored value. An empty value clears the field.
+func (d *DB) SetFieldValue(mediaFileID int64, field FieldDefinition, raw any) (any, error) {
+	value, err := field.Coerce(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		_, err := d.conn.Exec(
+			`DELETE FROM field_values WHERE media_file_id = ? AND name = ?`,
+			mediaFileID, field.Name,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("clearing field %q of media file %d: %w", field.Name, mediaFileID, err)
+		}
+		return nil, nil
+	}
+
+	var intValue, realValue, textValue any
+	switch v := value.(type) {
+	case int64:
+		intValue = v
+	case bool:
+		intValue = v
+	case float64:
+		realValue = v
+	default:
+		textValue = v
+	}
+
+	_, err = d.conn.Exec(
+		`INSERT INTO field_values (media_file_id, name, type, int_value, real_value, text_value)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (media_file_id, name) DO UPDATE SET
+			type = excluded.type,
+			int_value = excluded.int_value,
+			real_value = excluded.real_value,
+			text_value = excluded.text_value`,
+		mediaFileID, field.Name, field.Type, intValue, realValue, textValue,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("setting field %q of media file %d: %w", field.Name, mediaFileID, err)
+	}
+	return value, nil
+}
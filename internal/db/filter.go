@@ -0,0 +1,124 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidFilter is returned for a file filter with an unknown condition.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Queues select the files that still need a kind of work, so navigation
+// can skip the ones already handled.
+const (
+	QueueUnlabeled   = "unlabeled"    // Files without labels.
+	QueueUndescribed = "undescribed"  // Files without a description.
+	QueueNotDone     = "not_done"     // Files neither done nor skipped.
+	QueueNoKeyframes = "no_keyframes" // Video and audio files without keyframes.
+)
+
+// Queues lists every queue in the order they're shown.
+var Queues = []string{QueueUnlabeled, QueueUndescribed, QueueNotDone, QueueNoKeyframes}
+
+// MediaTypes lists the media types of files, in the order they're shown.
+var MediaTypes = []string{"image", "video", "audio", "text"}
+
+// FileFilter restricts navigation and exports to the media files matching
+// every set condition. The zero FileFilter matches every file.
+type FileFilter struct {
+	Label       string // Carries this label, not negated.
+	NoLabel     string // Carries this label negated, i.e. marked absent.
+	MediaType   string
+	Path        string // Glob over the path; * also matches "/". A trailing "/" matches a whole directory.
+	Status      string
+	Review      string // One of Reviews.
+	Description string // Text within the description or a description slot, ignoring case.
+	Queue       string
+	Query       string // In the query language, see Query.
+
+	Fields []FieldDefinition // The project's form fields, which type field: terms of the query.
+}
+
+// IsZero reports whether the filter matches every file.
+func (f FileFilter) IsZero() bool {
+	f.Fields = nil
+	return reflect.DeepEqual(f, FileFilter{})
+}
+
+// Validate checks the filter's conditions.
+func (f FileFilter) Validate() error {
+	if f.Status != "" {
+		if err := ValidateStatus(f.Status); err != nil {
+			return err
+		}
+	}
+	if f.Review != "" {
+		if err := ValidateReview(f.Review); err != nil {
+			return err
+		}
+	}
+	if f.MediaType != "" && !slices.Contains(MediaTypes, f.MediaType) {
+		return fmt.Errorf("%w: unknown media type %q, must be one of %v", ErrInvalidFilter, f.MediaType, MediaTypes)
+	}
+	if f.Queue != "" && !slices.Contains(Queues, f.Queue) {
+		return fmt.Errorf("%w: unknown queue %q, must be one of %v", ErrInvalidFilter, f.Queue, Queues)
+	}
+	if _, err := ParseQuery(f.Query, f.Fields); err != nil {
+		return err
+	}
+	return nil
+}
+
+// conditions returns the filter as SQL conditions on media_files aliased
+// as m, joined with AND, and their arguments. It returns "1 = 1" for the
+// zero filter so it can always follow a WHERE, and "0 = 1" for a query
+// that doesn't parse.
+func (f FileFilter) conditions() (string, []any) {
+	q, err := ParseQuery(f.Query, f.Fields)
+	if err != nil {
+		return "0 = 1", nil
+	}
+	for _, t := range []queryTerm{
+		{field: "label", value: f.Label},
+		{field: "nolabel", value: f.NoLabel},
+		{field: "type", value: f.MediaType},
+		{field: "path", value: f.Path},
+		{field: "desc", value: f.Description},
+		{field: "status", value: f.Status},
+		{field: "review", value: f.Review},
+	} {
+		if t.value != "" {
+			t.op = "="
+			q.terms = append(q.terms, t)
+		}
+	}
+
+	var where []string
+	var args []any
+	if !q.IsZero() {
+		cond, queryArgs := q.conditions()
+		where = append(where, cond)
+		args = append(args, queryArgs...)
+	}
+
+	switch f.Queue {
+	case QueueUnlabeled:
+		where = append(where, `NOT EXISTS (SELECT 1 FROM media_labels ml WHERE ml.media_file_id = m.id AND NOT ml.negated)`)
+	case QueueUndescribed:
+		where = append(where, `TRIM(m.description) = ''`)
+	case QueueNotDone:
+		where = append(where, `m.status NOT IN (?, ?)`)
+		args = append(args, StatusDone, StatusSkipped)
+	case QueueNoKeyframes:
+		where = append(where, `m.media_type IN ('video', 'audio')
+			AND NOT EXISTS (SELECT 1 FROM keyframes k WHERE k.media_file_id = m.id AND NOT k.pinned)`)
+	}
+
+	if len(where) == 0 {
+		return "1 = 1", nil
+	}
+	return strings.Join(where, " AND "), args
+}
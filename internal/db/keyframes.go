@@ -15,8 +15,10 @@ type Keyframe struct {
 	MediaFileID int64
 	TimestampMs int64
 	Description string
+	Transcript  string // Verbatim speech, separate from the description.
+	Language    string // Language code of the transcript, e.g. "en".
 	Pinned      bool
-	Labels      []Label
+	Labels      []AppliedLabel
 }
 
 // EnsurePinnedKeyframe creates the pinned 0:00 keyframe for a media file if it doesn't exist.
@@ -48,7 +50,7 @@ func (d *DB) EnsurePinnedKeyframe(mediaFileID int64) error {
 // Each keyframe includes its labels.
 func (d *DB) KeyframesForMediaFile(mediaFileID int64) ([]Keyframe, error) {
 	rows, err := d.conn.Query(
-		`SELECT id, media_file_id, timestamp_ms, description, pinned
+		`SELECT id, media_file_id, timestamp_ms, description, transcript, language, pinned
 		 FROM keyframes WHERE media_file_id = ?
 		 ORDER BY timestamp_ms ASC`,
 		mediaFileID,
@@ -61,7 +63,7 @@ func (d *DB) KeyframesForMediaFile(mediaFileID int64) ([]Keyframe, error) {
 	var keyframes []Keyframe
 	for rows.Next() {
 		var kf Keyframe
-		if err := rows.Scan(&kf.ID, &kf.MediaFileID, &kf.TimestampMs, &kf.Description, &kf.Pinned); err != nil {
+		if err := rows.Scan(&kf.ID, &kf.MediaFileID, &kf.TimestampMs, &kf.Description, &kf.Transcript, &kf.Language, &kf.Pinned); err != nil {
 			return nil, fmt.Errorf("scanning keyframe: %w", err)
 		}
 		keyframes = append(keyframes, kf)
@@ -72,7 +74,7 @@ func (d *DB) KeyframesForMediaFile(mediaFileID int64) ([]Keyframe, error) {
 
 	// Load labels for each keyframe.
 	for i := range keyframes {
-		labels, err := d.LabelsForKeyframe(keyframes[i].ID)
+		labels, err := d.AppliedLabels(KeyframeLabels, keyframes[i].ID)
 		if err != nil {
 			return nil, err
 		}
@@ -86,9 +88,9 @@ func (d *DB) KeyframesForMediaFile(mediaFileID int64) ([]Keyframe, error) {
 func (d *DB) GetKeyframe(id int64) (*Keyframe, error) {
 	kf := &Keyframe{}
 	err := d.conn.QueryRow(
-		`SELECT id, media_file_id, timestamp_ms, description, pinned FROM keyframes WHERE id = ?`,
+		`SELECT id, media_file_id, timestamp_ms, description, transcript, language, pinned FROM keyframes WHERE id = ?`,
 		id,
-	).Scan(&kf.ID, &kf.MediaFileID, &kf.TimestampMs, &kf.Description, &kf.Pinned)
+	).Scan(&kf.ID, &kf.MediaFileID, &kf.TimestampMs, &kf.Description, &kf.Transcript, &kf.Language, &kf.Pinned)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -96,7 +98,7 @@ func (d *DB) GetKeyframe(id int64) (*Keyframe, error) {
 		return nil, fmt.Errorf("fetching keyframe %d: %w", id, err)
 	}
 
-	labels, err := d.LabelsForKeyframe(kf.ID)
+	labels, err := d.AppliedLabels(KeyframeLabels, kf.ID)
 	if err != nil {
 		return nil, err
 	}
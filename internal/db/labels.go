@@ -1,13 +1,58 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 )
 
-// Label represents a reusable tag.
+// Label represents a reusable tag along with its annotation guidelines.
 type Label struct {
-	ID   int64
-	Name string
+	ID            int64
+	Name          string
+	Definition    string
+	PositiveNotes string
+	NegativeNotes string
+}
+
+// LabelUpdate holds the guideline fields to change on a label.
+// Nil fields are left untouched.
+type LabelUpdate struct {
+	Definition    *string
+	PositiveNotes *string
+	NegativeNotes *string
+}
+
+// labelColumns is the column list used when selecting labels aliased as "l".
+const labelColumns = `l.id, l.name, l.definition, l.positive_notes, l.negative_notes`
+
+// scanLabels reads all label rows selected with labelColumns.
+func scanLabels(rows *sql.Rows) ([]Label, error) {
+	defer rows.Close()
+
+	var labels []Label
+	for rows.Next() {
+		var l Label
+		if err := rows.Scan(&l.ID, &l.Name, &l.Definition, &l.PositiveNotes, &l.NegativeNotes); err != nil {
+			return nil, fmt.Errorf("scanning label: %w", err)
+		}
+		labels = append(labels, l)
+	}
+	return labels, rows.Err()
+}
+
+// scanAppliedLabels reads label rows selected with labelColumns followed by the assignment's attributes and polarity.
+func scanAppliedLabels(rows *sql.Rows) ([]AppliedLabel, error) {
+	defer rows.Close()
+
+	var labels []AppliedLabel
+	for rows.Next() {
+		var l AppliedLabel
+		if err := rows.Scan(&l.ID, &l.Name, &l.Definition, &l.PositiveNotes, &l.NegativeNotes, &l.Attributes, &l.Negated); err != nil {
+			return nil, fmt.Errorf("scanning label: %w", err)
+		}
+		labels = append(labels, l)
+	}
+	return labels, rows.Err()
 }
 
 // FindOrCreateLabel returns an existing label by name, or creates one.
@@ -18,129 +63,104 @@ func (d *DB) FindOrCreateLabel(name string) (*Label, error) {
 	}
 
 	l := &Label{}
-	err = d.conn.QueryRow(`SELECT id, name FROM labels WHERE name = ?`, name).Scan(&l.ID, &l.Name)
+	err = d.conn.QueryRow(
+		`SELECT `+labelColumns+` FROM labels l WHERE l.name = ?`, name,
+	).Scan(&l.ID, &l.Name, &l.Definition, &l.PositiveNotes, &l.NegativeNotes)
 	if err != nil {
 		return nil, fmt.Errorf("fetching label %q: %w", name, err)
 	}
 	return l, nil
 }
 
-// SearchLabels returns labels matching a prefix query, limited to 10 results.
-func (d *DB) SearchLabels(query string) ([]Label, error) {
-	rows, err := d.conn.Query(
-		`SELECT id, name FROM labels WHERE name LIKE ? ORDER BY name ASC LIMIT 10`,
-		query+"%",
-	)
-	if err != nil {
-		return nil, fmt.Errorf("searching labels for %q: %w", query, err)
-	}
-	defer rows.Close()
-
-	var labels []Label
-	for rows.Next() {
-		var l Label
-		if err := rows.Scan(&l.ID, &l.Name); err != nil {
-			return nil, fmt.Errorf("scanning label: %w", err)
-		}
-		labels = append(labels, l)
+// GetLabel returns a single label by ID.
+func (d *DB) GetLabel(id int64) (*Label, error) {
+	l := &Label{}
+	err := d.conn.QueryRow(
+		`SELECT `+labelColumns+` FROM labels l WHERE l.id = ?`, id,
+	).Scan(&l.ID, &l.Name, &l.Definition, &l.PositiveNotes, &l.NegativeNotes)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-	return labels, rows.Err()
-}
-
-// AddMediaLabel associates a label with a media file.
-func (d *DB) AddMediaLabel(mediaFileID, labelID int64) error {
-	_, err := d.conn.Exec(
-		`INSERT INTO media_labels (media_file_id, label_id) VALUES (?, ?) ON CONFLICT DO NOTHING`,
-		mediaFileID, labelID,
-	)
 	if err != nil {
-		return fmt.Errorf("adding label %d to media file %d: %w", labelID, mediaFileID, err)
+		return nil, fmt.Errorf("fetching label %d: %w", id, err)
 	}
-	return nil
+	return l, nil
 }
 
-// RemoveMediaLabel removes a label association from a media file.
-func (d *DB) RemoveMediaLabel(mediaFileID, labelID int64) error {
-	_, err := d.conn.Exec(
-		`DELETE FROM media_labels WHERE media_file_id = ? AND label_id = ?`,
-		mediaFileID, labelID,
-	)
+// AllLabels returns every label ordered by name.
+func (d *DB) AllLabels() ([]Label, error) {
+	rows, err := d.conn.Query(`SELECT ` + labelColumns + ` FROM labels l ORDER BY l.name ASC`)
 	if err != nil {
-		return fmt.Errorf("removing label %d from media file %d: %w", labelID, mediaFileID, err)
+		return nil, fmt.Errorf("fetching labels: %w", err)
 	}
-	return nil
+	return scanLabels(rows)
 }
 
-// LabelsForMediaFile returns all labels attached to a media file.
-func (d *DB) LabelsForMediaFile(mediaFileID int64) ([]Label, error) {
-	rows, err := d.conn.Query(
-		`SELECT l.id, l.name FROM labels l
-		 JOIN media_labels ml ON ml.label_id = l.id
-		 WHERE ml.media_file_id = ?
-		 ORDER BY l.name ASC`,
-		mediaFileID,
+// UpdateLabel changes a label's guideline fields.
+func (d *DB) UpdateLabel(id int64, update LabelUpdate) error {
+	result, err := d.conn.Exec(
+		`UPDATE labels SET
+			definition = COALESCE(?, definition),
+			positive_notes = COALESCE(?, positive_notes),
+			negative_notes = COALESCE(?, negative_notes)
+		 WHERE id = ?`,
+		update.Definition, update.PositiveNotes, update.NegativeNotes, id,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("fetching labels for media file %d: %w", mediaFileID, err)
+		return fmt.Errorf("updating label %d: %w", id, err)
 	}
-	defer rows.Close()
-
-	var labels []Label
-	for rows.Next() {
-		var l Label
-		if err := rows.Scan(&l.ID, &l.Name); err != nil {
-			return nil, fmt.Errorf("scanning label: %w", err)
-		}
-		labels = append(labels, l)
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("label %d not found", id)
 	}
-	return labels, rows.Err()
+	return nil
 }
 
-// AddKeyframeLabel associates a label with a keyframe.
-func (d *DB) AddKeyframeLabel(keyframeID, labelID int64) error {
+// AddLabelExample marks a media file as a visual example of a label.
+func (d *DB) AddLabelExample(labelID, mediaFileID int64) error {
 	_, err := d.conn.Exec(
-		`INSERT INTO keyframe_labels (keyframe_id, label_id) VALUES (?, ?) ON CONFLICT DO NOTHING`,
-		keyframeID, labelID,
+		`INSERT INTO label_examples (label_id, media_file_id) VALUES (?, ?) ON CONFLICT DO NOTHING`,
+		labelID, mediaFileID,
 	)
 	if err != nil {
-		return fmt.Errorf("adding label %d to keyframe %d: %w", labelID, keyframeID, err)
+		return fmt.Errorf("adding media file %d as example of label %d: %w", mediaFileID, labelID, err)
 	}
 	return nil
 }
 
-// RemoveKeyframeLabel removes a label association from a keyframe.
-func (d *DB) RemoveKeyframeLabel(keyframeID, labelID int64) error {
+// RemoveLabelExample removes a media file from a label's examples.
+func (d *DB) RemoveLabelExample(labelID, mediaFileID int64) error {
 	_, err := d.conn.Exec(
-		`DELETE FROM keyframe_labels WHERE keyframe_id = ? AND label_id = ?`,
-		keyframeID, labelID,
+		`DELETE FROM label_examples WHERE label_id = ? AND media_file_id = ?`,
+		labelID, mediaFileID,
 	)
 	if err != nil {
-		return fmt.Errorf("removing label %d from keyframe %d: %w", labelID, keyframeID, err)
+		return fmt.Errorf("removing media file %d from examples of label %d: %w", mediaFileID, labelID, err)
 	}
 	return nil
 }
 
-// LabelsForKeyframe returns all labels attached to a keyframe.
-func (d *DB) LabelsForKeyframe(keyframeID int64) ([]Label, error) {
+// LabelExamples returns the media files used as examples of a label, ordered by path.
+func (d *DB) LabelExamples(labelID int64) ([]MediaFile, error) {
 	rows, err := d.conn.Query(
-		`SELECT l.id, l.name FROM labels l
-		 JOIN keyframe_labels kl ON kl.label_id = l.id
-		 WHERE kl.keyframe_id = ?
-		 ORDER BY l.name ASC`,
-		keyframeID,
+		`SELECT `+mediaFileColumns+` FROM media_files m
+		 JOIN label_examples le ON le.media_file_id = m.id
+		 WHERE le.label_id = ?
+		 ORDER BY m.path ASC`,
+		labelID,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("fetching labels for keyframe %d: %w", keyframeID, err)
+		return nil, fmt.Errorf("fetching examples for label %d: %w", labelID, err)
 	}
 	defer rows.Close()
 
-	var labels []Label
+	var files []MediaFile
 	for rows.Next() {
-		var l Label
-		if err := rows.Scan(&l.ID, &l.Name); err != nil {
-			return nil, fmt.Errorf("scanning label: %w", err)
+		var m MediaFile
+		if err := scanMediaFile(rows, &m); err != nil {
+			return nil, fmt.Errorf("scanning media file: %w", err)
 		}
-		labels = append(labels, l)
+		files = append(files, m)
 	}
-	return labels, rows.Err()
+	return files, rows.Err()
 }
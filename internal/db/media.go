@@ -3,6 +3,7 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"slices"
 	"time"
 )
 
@@ -12,15 +13,34 @@ type MediaFile struct {
 	Path        string
 	MediaType   string
 	Description string
+	Width       int // Pixel size of images and videos, 0 until read.
+	Height      int
+	DurationMs  int64  // Length of videos and audio, 0 until reported by the browser.
+	Status      string // Workflow status, one of Statuses.
+	Review      string // ReviewApproved or ReviewRejected once reviewed, "" until then.
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
-// UpsertMediaFile inserts a media file or ignores it if the path already exists.
-func (d *DB) UpsertMediaFile(path, mediaType string) error {
+// mediaFileColumns lists the columns read by scanMediaFile, in order.
+const mediaFileColumns = `m.id, m.path, m.media_type, m.description, m.width, m.height, m.duration_ms, m.status, m.review, m.created_at, m.updated_at`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMediaFile(row rowScanner, m *MediaFile) error {
+	return row.Scan(&m.ID, &m.Path, &m.MediaType, &m.Description, &m.Width, &m.Height, &m.DurationMs, &m.Status, &m.Review, &m.CreatedAt, &m.UpdatedAt)
+}
+
+// UpsertMediaFile inserts a media file, or refreshes the size and
+// modification time of one already at the path.
+func (d *DB) UpsertMediaFile(path, mediaType string, size int64, modTime time.Time) error {
 	_, err := d.conn.Exec(
-		`INSERT INTO media_files (path, media_type) VALUES (?, ?) ON CONFLICT (path) DO NOTHING`,
-		path, mediaType,
+		`INSERT INTO media_files (path, media_type, size, modified_ms) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (path) DO UPDATE SET size = excluded.size, modified_ms = excluded.modified_ms`,
+		path, mediaType, size, modTime.UnixMilli(),
 	)
 	if err != nil {
 		return fmt.Errorf("upserting media file %q: %w", path, err)
@@ -31,10 +51,11 @@ func (d *DB) UpsertMediaFile(path, mediaType string) error {
 // GetMediaFile returns a single media file by ID.
 func (d *DB) GetMediaFile(id int64) (*MediaFile, error) {
 	m := &MediaFile{}
-	err := d.conn.QueryRow(
-		`SELECT id, path, media_type, description, created_at, updated_at FROM media_files WHERE id = ?`,
+	row := d.conn.QueryRow(
+		`SELECT `+mediaFileColumns+` FROM media_files m WHERE m.id = ?`,
 		id,
-	).Scan(&m.ID, &m.Path, &m.MediaType, &m.Description, &m.CreatedAt, &m.UpdatedAt)
+	)
+	err := scanMediaFile(row, m)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -44,21 +65,72 @@ func (d *DB) GetMediaFile(id int64) (*MediaFile, error) {
 	return m, nil
 }
 
-// FirstMediaFile returns the first media file ordered alphabetically by path.
-func (d *DB) FirstMediaFile() (*MediaFile, error) {
+// GetMediaFileByPath returns a single media file by its path relative to the project root.
+func (d *DB) GetMediaFileByPath(path string) (*MediaFile, error) {
 	m := &MediaFile{}
-	err := d.conn.QueryRow(
-		`SELECT id, path, media_type, description, created_at, updated_at FROM media_files ORDER BY path ASC LIMIT 1`,
-	).Scan(&m.ID, &m.Path, &m.MediaType, &m.Description, &m.CreatedAt, &m.UpdatedAt)
+	row := d.conn.QueryRow(
+		`SELECT `+mediaFileColumns+` FROM media_files m WHERE m.path = ?`,
+		path,
+	)
+	err := scanMediaFile(row, m)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("fetching first media file: %w", err)
+		return nil, fmt.Errorf("fetching media file %q: %w", path, err)
 	}
 	return m, nil
 }
 
+// MediaFiles returns the media files matching a filter, ordered
+// alphabetically by path.
+func (d *DB) MediaFiles(filter FileFilter) ([]MediaFile, error) {
+	return d.mediaFiles(filter, `m.path ASC`, -1, 0)
+}
+
+// MediaFilesPage returns up to limit media files matching a filter in the
+// project's navigation order, skipping the first offset. A negative limit
+// returns every remaining file.
+func (d *DB) MediaFilesPage(filter FileFilter, limit, offset int) ([]MediaFile, error) {
+	return d.mediaFiles(filter, `m.position ASC`, limit, offset)
+}
+
+func (d *DB) mediaFiles(filter FileFilter, orderBy string, limit, offset int) ([]MediaFile, error) {
+	where, args := filter.conditions()
+	rows, err := d.conn.Query(
+		`SELECT `+mediaFileColumns+` FROM media_files m WHERE `+where+`
+		 ORDER BY `+orderBy+` LIMIT ? OFFSET ?`,
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching media files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []MediaFile
+	for rows.Next() {
+		var m MediaFile
+		if err := scanMediaFile(rows, &m); err != nil {
+			return nil, fmt.Errorf("scanning media file: %w", err)
+		}
+		files = append(files, m)
+	}
+	return files, rows.Err()
+}
+
+// FirstMediaFile returns the first media file matching a filter in the
+// project's navigation order.
+func (d *DB) FirstMediaFile(filter FileFilter) (*MediaFile, error) {
+	files, err := d.MediaFilesPage(filter, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	return &files[0], nil
+}
+
 // NavigationInfo holds the previous and next file IDs for navigation.
 type NavigationInfo struct {
 	PrevID     int64
@@ -67,52 +139,65 @@ type NavigationInfo struct {
 	TotalCount int
 }
 
-// GetNavigation returns navigation context for a given media file.
-// Files are ordered alphabetically by path with wrap-around.
-func (d *DB) GetNavigation(currentID int64) (*NavigationInfo, error) {
-	// Get the current file's path for ordering context.
-	var currentPath string
-	err := d.conn.QueryRow(`SELECT path FROM media_files WHERE id = ?`, currentID).Scan(&currentPath)
+// GetNavigation returns navigation context for a given media file among
+// the files matching a filter. Files follow the project's navigation order
+// with wrap-around. The current file needn't match the filter itself, so
+// in a queue the next file is the next one still needing work.
+func (d *DB) GetNavigation(currentID int64, filter FileFilter) (*NavigationInfo, error) {
+	// Get the current file's position for ordering context.
+	var current int64
+	err := d.conn.QueryRow(`SELECT m.position FROM media_files m WHERE m.id = ?`, currentID).Scan(&current)
 	if err != nil {
-		return nil, fmt.Errorf("fetching path for media file %d: %w", currentID, err)
+		return nil, fmt.Errorf("fetching position of media file %d: %w", currentID, err)
 	}
 
 	nav := &NavigationInfo{}
+	where, args := filter.conditions()
+	position := append(slices.Clone(args), current)
 
 	// Total count.
-	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM media_files`).Scan(&nav.TotalCount); err != nil {
+	if err := d.conn.QueryRow(
+		`SELECT COUNT(*) FROM media_files m WHERE `+where, args...,
+	).Scan(&nav.TotalCount); err != nil {
 		return nil, fmt.Errorf("counting media files: %w", err)
 	}
 
-	// 1-based index of current file in alphabetical order.
+	// 1-based index of current file in navigation order.
 	if err := d.conn.QueryRow(
-		`SELECT COUNT(*) FROM media_files WHERE path <= ?`, currentPath,
+		`SELECT COUNT(*) FROM media_files m WHERE `+where+` AND m.position <= ?`, position...,
 	).Scan(&nav.Index); err != nil {
 		return nil, fmt.Errorf("computing index for media file %d: %w", currentID, err)
 	}
 
-	// Previous file: the one just before in alphabetical order, wrapping to last.
+	// Previous file: the one just before in navigation order, wrapping to last.
 	err = d.conn.QueryRow(
-		`SELECT id FROM media_files WHERE path < ? ORDER BY path DESC LIMIT 1`, currentPath,
+		`SELECT m.id FROM media_files m WHERE `+where+` AND m.position < ?
+		 ORDER BY m.position DESC LIMIT 1`, position...,
 	).Scan(&nav.PrevID)
 	if err == sql.ErrNoRows {
 		// Wrap to last file.
-		d.conn.QueryRow(`SELECT id FROM media_files ORDER BY path DESC LIMIT 1`).Scan(&nav.PrevID)
+		d.conn.QueryRow(`SELECT m.id FROM media_files m WHERE `+where+` ORDER BY m.position DESC LIMIT 1`, args...).Scan(&nav.PrevID)
 	} else if err != nil {
 		return nil, fmt.Errorf("fetching previous media file: %w", err)
 	}
 
-	// Next file: the one just after in alphabetical order, wrapping to first.
+	// Next file: the one just after in navigation order, wrapping to first.
 	err = d.conn.QueryRow(
-		`SELECT id FROM media_files WHERE path > ? ORDER BY path ASC LIMIT 1`, currentPath,
+		`SELECT m.id FROM media_files m WHERE `+where+` AND m.position > ?
+		 ORDER BY m.position ASC LIMIT 1`, position...,
 	).Scan(&nav.NextID)
 	if err == sql.ErrNoRows {
 		// Wrap to first file.
-		d.conn.QueryRow(`SELECT id FROM media_files ORDER BY path ASC LIMIT 1`).Scan(&nav.NextID)
+		d.conn.QueryRow(`SELECT m.id FROM media_files m WHERE `+where+` ORDER BY m.position ASC LIMIT 1`, args...).Scan(&nav.NextID)
 	} else if err != nil {
 		return nil, fmt.Errorf("fetching next media file: %w", err)
 	}
 
+	// Nothing matches the filter: stay on the current file.
+	if nav.TotalCount == 0 {
+		nav.PrevID, nav.NextID = currentID, currentID
+	}
+
 	return nav, nil
 }
 
@@ -132,6 +217,123 @@ func (d *DB) UpdateDescription(id int64, description string) error {
 	return nil
 }
 
+// NamedDescriptions returns the descriptions a media file has in the
+// project's description slots, keyed by slot name.
+func (d *DB) NamedDescriptions(mediaFileID int64) (map[string]string, error) {
+	rows, err := d.conn.Query(
+		`SELECT name, description FROM media_descriptions WHERE media_file_id = ?`,
+		mediaFileID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching descriptions of media file %d: %w", mediaFileID, err)
+	}
+	defer rows.Close()
+
+	descriptions := make(map[string]string)
+	for rows.Next() {
+		var name, description string
+		if err := rows.Scan(&name, &description); err != nil {
+			return nil, fmt.Errorf("scanning description: %w", err)
+		}
+		descriptions[name] = description
+	}
+	return descriptions, rows.Err()
+}
+
+// SetNamedDescription stores the description of a media file in a slot.
+// An empty description clears the slot.
+func (d *DB) SetNamedDescription(mediaFileID int64, name, description string) error {
+	var err error
+	if description == "" {
+		_, err = d.conn.Exec(
+			`DELETE FROM media_descriptions WHERE media_file_id = ? AND name = ?`,
+			mediaFileID, name,
+		)
+	} else {
+		_, err = d.conn.Exec(
+			`INSERT INTO media_descriptions (media_file_id, name, description) VALUES (?, ?, ?)
+			 ON CONFLICT DO UPDATE SET description = excluded.description`,
+			mediaFileID, name, description,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("updating %s description for media file %d: %w", name, mediaFileID, err)
+	}
+	return nil
+}
+
+// SetImageSize stores the pixel size of an image.
+func (d *DB) SetImageSize(id int64, width, height int) error {
+	result, err := d.conn.Exec(
+		`UPDATE media_files SET width = ?, height = ? WHERE id = ?`,
+		width, height, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating size of media file %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("media file %d not found", id)
+	}
+	return nil
+}
+
+// MetadataUpdate holds the pixel size and duration to store for a video or
+// audio file. Nil fields are left untouched.
+type MetadataUpdate struct {
+	Width      *int
+	Height     *int
+	DurationMs *int64
+}
+
+// SetMediaMetadata stores the pixel size and duration of a video or audio
+// file. Audio has no size.
+func (d *DB) SetMediaMetadata(id int64, update MetadataUpdate) error {
+	result, err := d.conn.Exec(
+		`UPDATE media_files SET
+			width = COALESCE(?, width),
+			height = COALESCE(?, height),
+			duration_ms = COALESCE(?, duration_ms)
+		 WHERE id = ?`,
+		update.Width, update.Height, update.DurationMs, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating metadata of media file %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("media file %d not found", id)
+	}
+	return nil
+}
+
+// CountMediaFiles returns the number of media files matching a filter.
+func (d *DB) CountMediaFiles(filter FileFilter) (int, error) {
+	where, args := filter.conditions()
+	var count int
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM media_files m WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting media files: %w", err)
+	}
+	return count, nil
+}
+
+// CountQueueScope counts the media files the filter's queue is drawn from:
+// those matching the filter without its queue, and of a media type the
+// queue applies to.
+func (d *DB) CountQueueScope(filter FileFilter) (int, error) {
+	queue := filter.Queue
+	filter.Queue = ""
+	where, args := filter.conditions()
+	if queue == QueueNoKeyframes {
+		where += ` AND m.media_type IN ('video', 'audio')`
+	}
+	var count int
+	if err := d.conn.QueryRow(`SELECT COUNT(*) FROM media_files m WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting media files: %w", err)
+	}
+	return count, nil
+}
+
 // MediaFileCount returns the total number of media files.
 func (d *DB) MediaFileCount() (int, error) {
 	var count int
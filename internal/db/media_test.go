@@ -0,0 +1,108 @@
+package db
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetNavigation(t *testing.T) {
+	d := openTestDB(t)
+	ids := addFiles(t, d, "a.png", "b.png", "c.png", "d.png")
+	addLabel(t, d, ids["b.png"], "cat", false)
+	addLabel(t, d, ids["d.png"], "cat", false)
+
+	tests := []struct {
+		name    string
+		current string
+		filter  FileFilter
+		want    NavigationInfo // With paths standing in for the IDs.
+		prev    string
+		next    string
+	}{
+		{"first wraps back", "a.png", FileFilter{}, NavigationInfo{Index: 1, TotalCount: 4}, "d.png", "b.png"},
+		{"middle", "b.png", FileFilter{}, NavigationInfo{Index: 2, TotalCount: 4}, "a.png", "c.png"},
+		{"last wraps forward", "d.png", FileFilter{}, NavigationInfo{Index: 4, TotalCount: 4}, "c.png", "a.png"},
+		{"filtered", "b.png", FileFilter{Label: "cat"}, NavigationInfo{Index: 1, TotalCount: 2}, "d.png", "d.png"},
+		{"current outside filter", "c.png", FileFilter{Label: "cat"}, NavigationInfo{Index: 1, TotalCount: 2}, "b.png", "d.png"},
+		{"before every filtered file", "a.png", FileFilter{Label: "cat"}, NavigationInfo{Index: 0, TotalCount: 2}, "d.png", "b.png"},
+		{"nothing matches", "c.png", FileFilter{Label: "dog"}, NavigationInfo{Index: 0, TotalCount: 0}, "c.png", "c.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nav, err := d.GetNavigation(ids[tt.current], tt.filter)
+			if err != nil {
+				t.Fatalf("GetNavigation() error = %v", err)
+			}
+			want := tt.want
+			want.PrevID, want.NextID = ids[tt.prev], ids[tt.next]
+			if *nav != want {
+				t.Errorf("GetNavigation() = %+v, want %+v", *nav, want)
+			}
+		})
+	}
+}
+
+func TestGetNavigationFollowsOrder(t *testing.T) {
+	d := openTestDB(t)
+	ids := addFiles(t, d, "a.png", "b.png", "c.png", "d.png", "e.png")
+	if err := d.SetNavigationOrder(Order{Name: OrderShuffle, Seed: 42}); err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.MediaFilesPage(FileFilter{}, -1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, f := range files {
+		order = append(order, f.Path)
+	}
+	if slices.IsSorted(order) {
+		t.Fatalf("shuffled order %v is sorted by path", order)
+	}
+
+	// Following the next links from the first file visits every file in
+	// order and wraps back to the first.
+	byID := make(map[int64]string)
+	for path, id := range ids {
+		byID[id] = path
+	}
+	var visited []string
+	id := ids[order[0]]
+	for range order {
+		visited = append(visited, byID[id])
+		nav, err := d.GetNavigation(id, FileFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nav.Index != len(visited) {
+			t.Errorf("index of %s = %d, want %d", byID[id], nav.Index, len(visited))
+		}
+		id = nav.NextID
+	}
+	if !slices.Equal(visited, order) {
+		t.Errorf("visited %v, want %v", visited, order)
+	}
+	if byID[id] != order[0] {
+		t.Errorf("next after the last file = %s, want %s", byID[id], order[0])
+	}
+}
+
+func TestGetNavigationKeepsPositions(t *testing.T) {
+	d := openTestDB(t)
+	ids := addFiles(t, d, "a.png", "b.png", "c.png")
+	if err := d.SetNavigationOrder(Order{Name: OrderLeastLabeled}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Labeling the first file would move it last if files were ranked on
+	// every request.
+	addLabel(t, d, ids["a.png"], "cat", false)
+	nav, err := d.GetNavigation(ids["a.png"], FileFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nav.Index != 1 || nav.NextID != ids["b.png"] {
+		t.Errorf("GetNavigation() = %+v, want a.png still first", *nav)
+	}
+}
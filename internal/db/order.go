@@ -0,0 +1,193 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+
+	"modernc.org/sqlite"
+)
+
+// ErrInvalidOrder is returned for a navigation order that doesn't exist.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// Navigation orders. Files with the same sort key follow in path order.
+// Files are ranked when the order is set, and navigation follows that
+// ranking, so labeling a file doesn't move it.
+const (
+	OrderPath         = "path"          // Alphabetically by path.
+	OrderShuffle      = "shuffle"       // Shuffled, the same way for the same seed.
+	OrderModified     = "modified"      // Least recently modified first.
+	OrderSize         = "size"          // Smallest first.
+	OrderDuration     = "duration"      // Shortest video or audio first, files of unknown length last.
+	OrderLeastLabeled = "least_labeled" // Fewest labels first.
+)
+
+// Orders lists every navigation order in the order they're shown.
+var Orders = []string{OrderPath, OrderShuffle, OrderModified, OrderSize, OrderDuration, OrderLeastLabeled}
+
+// Order is the order files are navigated in. Seed picks the shuffle.
+type Order struct {
+	Name string
+	Seed int64
+}
+
+// ValidateOrder checks that an order is one of Orders.
+func ValidateOrder(name string) error {
+	if !slices.Contains(Orders, name) {
+		return fmt.Errorf("%w: %q, must be one of %v", ErrInvalidOrder, name, Orders)
+	}
+	return nil
+}
+
+func init() {
+	// jli_shuffle(id, seed) is the sort key of a file in a seeded shuffle:
+	// a hash, so neighboring files end up far apart.
+	sqlite.MustRegisterDeterministicScalarFunction("jli_shuffle", 2, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		id, _ := args[0].(int64)
+		seed, _ := args[1].(int64)
+		var b [16]byte
+		binary.LittleEndian.PutUint64(b[:8], uint64(seed))
+		binary.LittleEndian.PutUint64(b[8:], uint64(id))
+		h := fnv.New64a()
+		h.Write(b[:])
+		return int64(h.Sum64() >> 1), nil
+	})
+}
+
+// key returns the order's sort keys as SQL expressions on media_files
+// aliased as m, separated by commas, and their arguments.
+func (o Order) key() (string, []any) {
+	switch o.Name {
+	case OrderShuffle:
+		return `jli_shuffle(m.id, ?)`, []any{o.Seed}
+	case OrderModified:
+		return `m.modified_ms`, nil
+	case OrderSize:
+		return `m.size`, nil
+	case OrderDuration:
+		// The duration is known once the file has been opened.
+		return `m.duration_ms = 0, m.duration_ms`, nil
+	case OrderLeastLabeled:
+		return `(SELECT COUNT(*) FROM media_labels ml WHERE ml.media_file_id = m.id AND NOT ml.negated)`, nil
+	}
+	return `m.path`, nil
+}
+
+// annotated reports whether the order's keys change as files are
+// annotated, rather than only when the files themselves change.
+func (o Order) annotated() bool {
+	return o.Name == OrderDuration || o.Name == OrderLeastLabeled
+}
+
+// rankFiles stores each file's position in the order. With onlyNew, only
+// files without a position are ranked, after all the others.
+func rankFiles(tx *sql.Tx, order Order, onlyNew bool) error {
+	where := `1 = 1`
+	var last int64
+	if onlyNew {
+		where = `m.position IS NULL`
+		if err := tx.QueryRow(`SELECT COALESCE(MAX(position), 0) FROM media_files`).Scan(&last); err != nil {
+			return fmt.Errorf("fetching last position: %w", err)
+		}
+	}
+
+	key, args := order.key()
+	_, err := tx.Exec(
+		`UPDATE media_files SET position = ranked.position
+		 FROM (SELECT m.id, ? + ROW_NUMBER() OVER (ORDER BY `+key+`, m.path) AS position
+			FROM media_files m WHERE `+where+`) AS ranked
+		 WHERE ranked.id = media_files.id`,
+		append([]any{last}, args...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("ranking media files: %w", err)
+	}
+	return nil
+}
+
+// NavigationOrder returns the order the project's files are navigated in,
+// alphabetical unless another was set.
+func (d *DB) NavigationOrder() (Order, error) {
+	order := Order{Name: OrderPath}
+	rows, err := d.conn.Query(`SELECT name, value FROM settings WHERE name IN ('order', 'order_seed')`)
+	if err != nil {
+		return order, fmt.Errorf("fetching navigation order: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return order, fmt.Errorf("scanning setting: %w", err)
+		}
+		switch name {
+		case "order":
+			if ValidateOrder(value) == nil {
+				order.Name = value
+			}
+		case "order_seed":
+			order.Seed, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return order, rows.Err()
+}
+
+// SetNavigationOrder stores the order the project's files are navigated
+// in, and ranks every file in it.
+func (d *DB) SetNavigationOrder(order Order) error {
+	if err := ValidateOrder(order.Name); err != nil {
+		return err
+	}
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning order update: %w", err)
+	}
+	defer tx.Rollback()
+
+	for name, value := range map[string]string{
+		"order":      order.Name,
+		"order_seed": strconv.FormatInt(order.Seed, 10),
+	} {
+		_, err := tx.Exec(
+			`INSERT INTO settings (name, value) VALUES (?, ?)
+			 ON CONFLICT (name) DO UPDATE SET value = excluded.value`,
+			name, value,
+		)
+		if err != nil {
+			return fmt.Errorf("storing setting %q: %w", name, err)
+		}
+	}
+	if err := rankFiles(tx, order, false); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RefreshNavigationOrder ranks the files found since the order was set.
+// Orders on the files themselves are ranked again, placing new files
+// among the others; orders on annotations keep their ranking, so files
+// don't move between sessions, and new files follow at the end.
+func (d *DB) RefreshNavigationOrder() error {
+	order, err := d.NavigationOrder()
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning ranking: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := rankFiles(tx, order, order.annotated()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,332 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidQuery is returned for a query that can't be parsed, or has a
+// term with an invalid value.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Query selects media files with a small query language, e.g.
+//
+//	label:dog -label:cat type:video status:done path:2024/** desc:"on a leash" keyframes>3
+//
+// Terms are separated by spaces and must all match; a leading "-" negates
+// a term. Supported terms:
+//
+//	label:NAME           carries the label, not negated
+//	nolabel:NAME         carries the label negated, i.e. marked absent
+//	type:TYPE            media type: image, video, audio or text
+//	status:STATUS        workflow status
+//	review:REVIEW        approved, rejected, or pending: done but not yet reviewed
+//	path:GLOB            path matches the glob; * and ** also match "/"
+//	desc:TEXT            description or a description slot contains the text
+//	field:NAME=VALUE     form field compares to the value; also !=, and
+//	                     >, >=, <, <= on numbers and text
+//	keyframes>N          count of keyframes (besides the pinned one), labels,
+//	                     regions, segments, spans or tracks compares to N;
+//	                     also >=, <, <=, = and :
+//
+// Any other word, or "a quoted phrase", must appear in the file's
+// description or a description slot, as in a full-text search. Values with spaces are quoted:
+// desc:"on a leash".
+type Query struct {
+	words []queryWord
+	terms []queryTerm
+}
+
+// queryWord is a word or phrase of full-text search.
+type queryWord struct {
+	text    string
+	negated bool
+}
+
+// queryTerm is a field term, such as label:dog or keyframes>3.
+type queryTerm struct {
+	field   string
+	op      string // One of =, !=, >, >=, <, <=.
+	value   string // The form field's name in field terms.
+	negated bool
+	typed   any    // The value of a field term, coerced to the form field's type.
+	column  string // The field_values column holding values of that type.
+}
+
+// countFields are the annotations a query can count per file, with the
+// subquery counting them.
+var countFields = map[string]string{
+	"keyframes": `SELECT COUNT(*) FROM keyframes k WHERE k.media_file_id = m.id AND NOT k.pinned`,
+	"labels":    `SELECT COUNT(*) FROM media_labels ml WHERE ml.media_file_id = m.id AND NOT ml.negated`,
+	"regions":   `SELECT COUNT(*) FROM regions r WHERE r.media_file_id = m.id`,
+	"segments":  `SELECT COUNT(*) FROM segments s WHERE s.media_file_id = m.id`,
+	"spans":     `SELECT COUNT(*) FROM spans s WHERE s.media_file_id = m.id`,
+	"tracks":    `SELECT COUNT(*) FROM tracks t WHERE t.media_file_id = m.id`,
+}
+
+var (
+	// termPattern splits a term into its field, operator and value.
+	termPattern = regexp.MustCompile(`^([a-z]+)(>=|<=|!=|:|>|<|=)(.*)$`)
+	// fieldTermPattern splits the value of a field: term the same way.
+	fieldTermPattern = regexp.MustCompile(`^([A-Za-z0-9_-]+)(>=|<=|!=|>|<|=|:)(.*)$`)
+)
+
+// ParseQuery parses a query. The project's form fields type the values
+// of field: terms. An empty query matches every file.
+func ParseQuery(s string, fields []FieldDefinition) (*Query, error) {
+	tokens, err := splitQuery(s)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{}
+	for _, token := range tokens {
+		negated := false
+		if len(token) > 1 && token[0] == '-' {
+			negated = true
+			token = token[1:]
+		}
+
+		if strings.HasPrefix(token, `"`) {
+			if phrase := strings.TrimSpace(unquote(token)); phrase != "" {
+				q.words = append(q.words, queryWord{text: phrase, negated: negated})
+			}
+			continue
+		}
+
+		parts := termPattern.FindStringSubmatch(token)
+		if parts == nil {
+			q.words = append(q.words, queryWord{text: token, negated: negated})
+			continue
+		}
+
+		term, err := parseTerm(parts[1], parts[2], unquote(parts[3]), fields)
+		if err != nil {
+			return nil, err
+		}
+		term.negated = negated
+		q.terms = append(q.terms, term)
+	}
+	return q, nil
+}
+
+// splitQuery splits a query at spaces outside double quotes.
+func splitQuery(s string) ([]string, error) {
+	var tokens []string
+	var token strings.Builder
+	quoted := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			token.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if token.Len() > 0 {
+				tokens = append(tokens, token.String())
+				token.Reset()
+			}
+		default:
+			token.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+	}
+	if token.Len() > 0 {
+		tokens = append(tokens, token.String())
+	}
+	return tokens, nil
+}
+
+// unquote removes the double quotes around a value, if it has them.
+func unquote(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// parseTerm checks a field term and normalizes its operator.
+func parseTerm(field, op, value string, fields []FieldDefinition) (queryTerm, error) {
+	if op == ":" {
+		op = "="
+	}
+	term := queryTerm{field: field, op: op, value: value}
+
+	if _, ok := countFields[field]; ok {
+		if _, err := strconv.Atoi(value); err != nil || op == "!=" {
+			return term, fmt.Errorf("%w: %s needs a comparison with a whole number, e.g. %s>3", ErrInvalidQuery, field, field)
+		}
+		return term, nil
+	}
+
+	if field == "field" {
+		parts := fieldTermPattern.FindStringSubmatch(value)
+		if parts == nil {
+			return term, fmt.Errorf("%w: field needs a name, a comparison and a value, e.g. field:people>2", ErrInvalidQuery)
+		}
+		term.value = parts[1]
+		term.op = parts[2]
+		if term.op == ":" {
+			term.op = "="
+		}
+
+		i := slices.IndexFunc(fields, func(f FieldDefinition) bool { return f.Name == term.value })
+		if i < 0 {
+			return term, fmt.Errorf("%w: unknown form field %q", ErrInvalidQuery, term.value)
+		}
+		def := fields[i]
+		if (def.Type == FieldBool || def.Type == AttributeEnum) && term.op != "=" && term.op != "!=" {
+			return term, fmt.Errorf("%w: %s can only be compared with = and !=", ErrInvalidQuery, def.Name)
+		}
+		typed, err := def.Coerce(unquote(parts[3]))
+		if err != nil {
+			return term, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+		if typed == nil {
+			return term, fmt.Errorf("%w: field %s needs a value", ErrInvalidQuery, def.Name)
+		}
+		term.typed = typed
+		term.column = fieldValueColumn(def.Type)
+		return term, nil
+	}
+
+	if op != "=" {
+		return term, fmt.Errorf("%w: %s only supports %s:value", ErrInvalidQuery, field, field)
+	}
+	if value == "" {
+		return term, fmt.Errorf("%w: %s needs a value", ErrInvalidQuery, field)
+	}
+	switch field {
+	case "label", "nolabel", "path", "desc":
+	case "type":
+		if !slices.Contains(MediaTypes, value) {
+			return term, fmt.Errorf("%w: unknown media type %q, must be one of %v", ErrInvalidQuery, value, MediaTypes)
+		}
+	case "status":
+		if err := ValidateStatus(value); err != nil {
+			return term, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+	case "review":
+		if err := ValidateReview(value); err != nil {
+			return term, fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+		}
+	default:
+		return term, fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, field)
+	}
+	return term, nil
+}
+
+// IsZero reports whether the query matches every file.
+func (q *Query) IsZero() bool {
+	return len(q.words) == 0 && len(q.terms) == 0
+}
+
+// matchExpression returns the query's words as an FTS5 query, quoting
+// each so FTS5 syntax in them is taken literally. Negated words are
+// excluded with NOT, which FTS5 only allows after a positive word. It
+// returns "" without positive words.
+func (q *Query) matchExpression() string {
+	var positive, negative []string
+	for _, w := range q.words {
+		quoted := `"` + strings.ReplaceAll(w.text, `"`, `""`) + `"`
+		if w.negated {
+			negative = append(negative, quoted)
+		} else {
+			positive = append(positive, quoted)
+		}
+	}
+	if len(positive) == 0 {
+		return ""
+	}
+	expr := strings.Join(positive, " ")
+	for _, n := range negative {
+		expr += " NOT " + n
+	}
+	return expr
+}
+
+// wordMatches selects the IDs of files whose description, or one of their
+// description slots, matches a full-text search.
+const wordMatches = `SELECT rowid FROM media_files_fts WHERE media_files_fts MATCH ?
+	UNION SELECT media_file_id FROM media_descriptions_fts WHERE media_descriptions_fts MATCH ?`
+
+// conditions returns the whole query as SQL conditions on media_files
+// aliased as m, joined with AND, and their arguments.
+func (q *Query) conditions() (string, []any) {
+	where, args := q.termConditions()
+	for _, w := range q.words {
+		cond := `m.id IN (` + wordMatches + `)`
+		if w.negated {
+			cond = `m.id NOT IN (` + wordMatches + `)`
+		}
+		where = append(where, cond)
+		quoted := `"` + strings.ReplaceAll(w.text, `"`, `""`) + `"`
+		args = append(args, quoted, quoted)
+	}
+	return strings.Join(where, " AND "), args
+}
+
+// termConditions returns the SQL conditions of the query's field terms,
+// leaving out its words.
+func (q *Query) termConditions() ([]string, []any) {
+	var where []string
+	var args []any
+	for _, t := range q.terms {
+		cond, termArgs := t.condition()
+		if t.negated {
+			cond = `NOT (` + cond + `)`
+		}
+		where = append(where, cond)
+		args = append(args, termArgs...)
+	}
+	return where, args
+}
+
+// condition returns the term as an SQL condition on media_files aliased as m.
+func (t queryTerm) condition() (string, []any) {
+	if count, ok := countFields[t.field]; ok {
+		n, _ := strconv.Atoi(t.value)
+		return `(` + count + `) ` + t.op + ` ?`, []any{n}
+	}
+
+	switch t.field {
+	case "label":
+		return `EXISTS (
+			SELECT 1 FROM media_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.media_file_id = m.id AND l.name = ? AND NOT ml.negated)`, []any{t.value}
+	case "nolabel":
+		return `EXISTS (
+			SELECT 1 FROM media_labels ml JOIN labels l ON l.id = ml.label_id
+			WHERE ml.media_file_id = m.id AND l.name = ? AND ml.negated)`, []any{t.value}
+	case "type":
+		return `m.media_type = ?`, []any{t.value}
+	case "status":
+		return `m.status = ?`, []any{t.value}
+	case "review":
+		if t.value == ReviewPending {
+			return `(m.status = ? AND m.review = '')`, []any{StatusDone}
+		}
+		return `m.review = ?`, []any{t.value}
+	case "path":
+		pattern := strings.ReplaceAll(t.value, "**", "*")
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "*"
+		}
+		return `replace(m.path, '\', '/') GLOB ?`, []any{pattern}
+	case "desc":
+		return `(instr(lower(m.description), lower(?)) > 0
+			OR EXISTS (SELECT 1 FROM media_descriptions md
+				WHERE md.media_file_id = m.id AND instr(lower(md.description), lower(?)) > 0))`, []any{t.value, t.value}
+	case "field":
+		return `EXISTS (SELECT 1 FROM field_values fv
+			WHERE fv.media_file_id = m.id AND fv.name = ?
+			AND fv.` + t.column + ` ` + t.op + ` ?)`, []any{t.value, t.typed}
+	}
+	return `0 = 1`, nil
+}
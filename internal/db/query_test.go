@@ -0,0 +1,157 @@
+package db
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// testFields are the form fields queries are parsed with in tests.
+var testFields = []FieldDefinition{
+	{Name: "people", Type: AttributeInt},
+	{Name: "exposure", Type: AttributeFloat},
+	{Name: "alt_text", Type: AttributeString},
+	{Name: "outdoors", Type: FieldBool},
+	{Name: "light", Type: AttributeEnum, Options: []string{"day", "night"}},
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unterminated quote", `"on a leash`},
+		{"unknown term", "color:red"},
+		{"label without value", "label:"},
+		{"label comparison", "label>cat"},
+		{"unknown status", "status:finished"},
+		{"unknown review", "review:maybe"},
+		{"unknown media type", "type:vid"},
+		{"count without number", "keyframes>some"},
+		{"count inequality", "keyframes!=3"},
+		{"field without comparison", "field:people"},
+		{"unknown field", "field:animals=3"},
+		{"field of wrong type", "field:people=many"},
+		{"field without value", "field:people="},
+		{"enum outside options", "field:light=dusk"},
+		{"ordered bool", "field:outdoors>false"},
+		{"ordered enum", "field:light<night"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseQuery(tt.query, testFields); !errors.Is(err, ErrInvalidQuery) {
+				t.Errorf("ParseQuery(%q) error = %v, want ErrInvalidQuery", tt.query, err)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	d := openTestDB(t)
+	ids := addFiles(t, d, "a.png", "b.png", "c.mp4", "d.txt")
+
+	addLabel(t, d, ids["a.png"], "cat", false)
+	addLabel(t, d, ids["b.png"], "dog", false)
+	addLabel(t, d, ids["b.png"], "cat", true)
+	if err := d.UpdateDescription(ids["a.png"], "A black cat on a leash"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.UpdateDescription(ids["b.png"], "A dog pulling on its leash"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetNamedDescription(ids["c.mp4"], "scene", "A zebra crossing"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.CreateKeyframe(ids["c.mp4"], 1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetStatus(ids["d.txt"], StatusDone); err != nil {
+		t.Fatal(err)
+	}
+	for path, values := range map[string]map[string]any{
+		"a.png": {"people": 3.0, "exposure": "0.5", "alt_text": "2024", "outdoors": true, "light": "night"},
+		"b.png": {"people": 1.0, "exposure": 1.5, "alt_text": "a dog", "outdoors": false, "light": "day"},
+	} {
+		for _, f := range testFields {
+			if _, err := d.SetFieldValue(ids[path], f, values[f.Name]); err != nil {
+				t.Fatalf("setting %s of %s: %v", f.Name, path, err)
+			}
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"a.png", "b.png", "c.mp4", "d.txt"}},
+		{"label:cat", []string{"a.png"}},
+		{"-label:cat", []string{"b.png", "c.mp4", "d.txt"}},
+		{"nolabel:cat", []string{"b.png"}},
+		{"type:image -label:dog", []string{"a.png"}},
+		{"status:done", []string{"d.txt"}},
+		{"path:*.png", []string{"a.png", "b.png"}},
+		{"keyframes>0", []string{"c.mp4"}},
+		{"labels=0", []string{"c.mp4", "d.txt"}},
+		{"labels:1", []string{"a.png", "b.png"}},
+
+		// Words and phrases.
+		{"leash", []string{"a.png", "b.png"}},
+		{"leash -black", []string{"b.png"}},
+		{"-leash", []string{"c.mp4", "d.txt"}},
+		{`"on a leash"`, []string{"a.png"}},
+		{`"leash on"`, nil},
+		{`-"on a leash"`, []string{"b.png", "c.mp4", "d.txt"}},
+		{"zebra", []string{"c.mp4"}},
+		{`desc:"cat on"`, []string{"a.png"}},
+		{`desc:ZEBRA`, []string{"c.mp4"}},
+
+		// Form fields, typed by their definitions.
+		{"field:people>2", []string{"a.png"}},
+		{"field:people<=3", []string{"a.png", "b.png"}},
+		{"field:people!=3", []string{"b.png"}},
+		{"-field:people=3", []string{"b.png", "c.mp4", "d.txt"}},
+		{"field:exposure>=1", []string{"b.png"}},
+		{"field:exposure<1.5", []string{"a.png"}},
+		{"field:alt_text=2024", []string{"a.png"}},
+		{`field:alt_text="a dog"`, []string{"b.png"}},
+		{"field:outdoors=true", []string{"a.png"}},
+		{"field:outdoors:false", []string{"b.png"}},
+		{"field:light=night", []string{"a.png"}},
+		{"field:light!=night", []string{"b.png"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := paths(t, d, FileFilter{Query: tt.query, Fields: testFields})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("query %q matched %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileFilterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  FileFilter
+		wantErr error
+	}{
+		{"zero", FileFilter{}, nil},
+		{"media type", FileFilter{MediaType: "audio"}, nil},
+		{"unknown media type", FileFilter{MediaType: "photo"}, ErrInvalidFilter},
+		{"unknown queue", FileFilter{Queue: "later"}, ErrInvalidFilter},
+		{"unknown status", FileFilter{Status: "finished"}, ErrInvalidStatus},
+		{"invalid query", FileFilter{Query: "type:photo"}, ErrInvalidQuery},
+		{"field without definitions", FileFilter{Query: "field:people>2"}, ErrInvalidQuery},
+		{"field", FileFilter{Query: "field:people>2", Fields: testFields}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filter.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
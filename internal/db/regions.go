@@ -0,0 +1,362 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidRegion is returned when a region's shape lies outside the image or has no area.
+var ErrInvalidRegion = errors.New("invalid region")
+
+// Box is a rectangle in coordinates normalized to the image size,
+// with the origin at the top-left corner.
+type Box struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// Validate checks that the box has an area and fits within the image.
+func (b Box) Validate() error {
+	if b.Width <= 0 || b.Height <= 0 {
+		return fmt.Errorf("%w: box must have a positive width and height", ErrInvalidRegion)
+	}
+	if b.X < 0 || b.Y < 0 || b.X+b.Width > 1.0001 || b.Y+b.Height > 1.0001 {
+		return fmt.Errorf("%w: box must lie within the image", ErrInvalidRegion)
+	}
+	return nil
+}
+
+// Region is an annotated area of an image. Every region has a bounding box;
+// polygon regions also have their outline, mask regions their pixels and
+// keypoint regions their points, following the skeleton of a label.
+type Region struct {
+	ID          int64
+	MediaFileID int64
+	Kind        string
+	Box
+	Points      Polygon
+	Mask        Mask
+	SkeletonID  int64 // ID of the label whose skeleton the keypoints follow.
+	Keypoints   Keypoints
+	Description string
+	Labels      []AppliedLabel
+}
+
+// regionColumns lists the columns read by scanRegion, in order.
+const regionColumns = `id, media_file_id, kind, x, y, width, height, points, mask,
+	COALESCE(skeleton_label_id, 0), keypoints, description`
+
+func scanRegion(row rowScanner, r *Region) error {
+	return row.Scan(&r.ID, &r.MediaFileID, &r.Kind, &r.X, &r.Y, &r.Width, &r.Height, &r.Points, &r.Mask,
+		&r.SkeletonID, &r.Keypoints, &r.Description)
+}
+
+// RegionsForMediaFile returns all regions of a media file in creation order.
+// Each region includes its labels.
+func (d *DB) RegionsForMediaFile(mediaFileID int64) ([]Region, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+regionColumns+`
+		 FROM regions WHERE media_file_id = ?
+		 ORDER BY id ASC`,
+		mediaFileID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching regions for media file %d: %w", mediaFileID, err)
+	}
+	defer rows.Close()
+
+	var regions []Region
+	for rows.Next() {
+		var r Region
+		if err := scanRegion(rows, &r); err != nil {
+			return nil, fmt.Errorf("scanning region: %w", err)
+		}
+		regions = append(regions, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range regions {
+		labels, err := d.AppliedLabels(RegionLabels, regions[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		regions[i].Labels = labels
+	}
+
+	return regions, nil
+}
+
+// GetRegion returns a single region by ID.
+func (d *DB) GetRegion(id int64) (*Region, error) {
+	r := &Region{}
+	row := d.conn.QueryRow(`SELECT `+regionColumns+` FROM regions WHERE id = ?`, id)
+	err := scanRegion(row, r)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching region %d: %w", id, err)
+	}
+
+	labels, err := d.AppliedLabels(RegionLabels, r.ID)
+	if err != nil {
+		return nil, err
+	}
+	r.Labels = labels
+
+	return r, nil
+}
+
+// CreateRegion adds a box region to a media file.
+func (d *DB) CreateRegion(mediaFileID int64, box Box) (*Region, error) {
+	if err := box.Validate(); err != nil {
+		return nil, err
+	}
+	return d.insertRegion(mediaFileID, RegionBox, box, nil, Mask{})
+}
+
+// CreatePolygonRegion adds a polygon region to an image. The vertices are
+// snapped to the image's pixel grid, so its size must already be stored.
+func (d *DB) CreatePolygonRegion(mediaFileID int64, points Polygon) (*Region, error) {
+	width, height, err := d.imageSize(mediaFileID)
+	if err != nil {
+		return nil, err
+	}
+
+	points = points.Snap(width, height)
+	if err := points.Validate(); err != nil {
+		return nil, err
+	}
+	return d.insertRegion(mediaFileID, RegionPolygon, points.Bounds(), points, Mask{})
+}
+
+// CreateMaskRegion adds a mask region to an image. The mask must cover the
+// image's stored pixel size.
+func (d *DB) CreateMaskRegion(mediaFileID int64, mask Mask) (*Region, error) {
+	width, height, err := d.imageSize(mediaFileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mask.Validate(width, height); err != nil {
+		return nil, err
+	}
+	return d.insertRegion(mediaFileID, RegionMask, mask.Bounds(), nil, mask)
+}
+
+// CreateKeypointsRegion places an instance of a label's skeleton on an image
+// and applies the label to it. Placed keypoints are snapped to the pixel grid
+// when the image's size is known.
+func (d *DB) CreateKeypointsRegion(mediaFileID, labelID int64, keypoints Keypoints) (*Region, error) {
+	skeleton, err := d.LabelSkeleton(labelID)
+	if err != nil {
+		return nil, err
+	}
+	if skeleton == nil {
+		return nil, fmt.Errorf("%w: label %d has no skeleton", ErrInvalidRegion, labelID)
+	}
+
+	if width, height, err := d.imageSize(mediaFileID); err == nil {
+		keypoints = keypoints.Snap(width, height)
+	}
+	if err := keypoints.Validate(skeleton); err != nil {
+		return nil, err
+	}
+
+	box := keypoints.Bounds()
+	result, err := d.conn.Exec(
+		`INSERT INTO regions (media_file_id, kind, x, y, width, height, skeleton_label_id, keypoints)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		mediaFileID, RegionKeypoints, box.X, box.Y, box.Width, box.Height, labelID, keypoints,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating keypoints region for media file %d: %w", mediaFileID, err)
+	}
+
+	id, _ := result.LastInsertId()
+	if err := d.AddAppliedLabel(RegionLabels, id, labelID, false); err != nil {
+		return nil, err
+	}
+	return d.GetRegion(id)
+}
+
+func (d *DB) insertRegion(mediaFileID int64, kind string, box Box, points Polygon, mask Mask) (*Region, error) {
+	result, err := d.conn.Exec(
+		`INSERT INTO regions (media_file_id, kind, x, y, width, height, points, mask)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		mediaFileID, kind, box.X, box.Y, box.Width, box.Height, points, mask,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s region for media file %d: %w", kind, mediaFileID, err)
+	}
+
+	id, _ := result.LastInsertId()
+	return d.GetRegion(id)
+}
+
+// imageSize returns the stored pixel size of an image.
+func (d *DB) imageSize(mediaFileID int64) (int, int, error) {
+	var width, height int
+	err := d.conn.QueryRow(
+		`SELECT width, height FROM media_files WHERE id = ?`, mediaFileID,
+	).Scan(&width, &height)
+	if err == sql.ErrNoRows {
+		return 0, 0, fmt.Errorf("media file %d not found", mediaFileID)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("fetching size of media file %d: %w", mediaFileID, err)
+	}
+	if width == 0 || height == 0 {
+		return 0, 0, fmt.Errorf("%w: the size of this image is unknown", ErrInvalidRegion)
+	}
+	return width, height, nil
+}
+
+// UpdateRegionBox moves or resizes a box region.
+func (d *DB) UpdateRegionBox(id int64, box Box) error {
+	if err := box.Validate(); err != nil {
+		return err
+	}
+
+	result, err := d.conn.Exec(
+		`UPDATE regions SET x = ?, y = ?, width = ?, height = ? WHERE id = ? AND kind = ?`,
+		box.X, box.Y, box.Width, box.Height, id, RegionBox,
+	)
+	if err != nil {
+		return fmt.Errorf("updating box of region %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("box region %d not found", id)
+	}
+	return nil
+}
+
+// UpdateRegionPolygon replaces the outline of a polygon region, snapping
+// its vertices to the pixel grid and updating its bounding box.
+func (d *DB) UpdateRegionPolygon(id int64, points Polygon) error {
+	region, err := d.GetRegion(id)
+	if err != nil {
+		return err
+	}
+	if region == nil || region.Kind != RegionPolygon {
+		return fmt.Errorf("polygon region %d not found", id)
+	}
+
+	width, height, err := d.imageSize(region.MediaFileID)
+	if err != nil {
+		return err
+	}
+
+	points = points.Snap(width, height)
+	if err := points.Validate(); err != nil {
+		return err
+	}
+
+	box := points.Bounds()
+	_, err = d.conn.Exec(
+		`UPDATE regions SET x = ?, y = ?, width = ?, height = ?, points = ? WHERE id = ?`,
+		box.X, box.Y, box.Width, box.Height, points, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating polygon of region %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateRegionKeypoints moves the keypoints of a keypoint region or changes
+// their visibility, and updates its bounding box.
+func (d *DB) UpdateRegionKeypoints(id int64, keypoints Keypoints) error {
+	region, err := d.GetRegion(id)
+	if err != nil {
+		return err
+	}
+	if region == nil || region.Kind != RegionKeypoints {
+		return fmt.Errorf("keypoints region %d not found", id)
+	}
+
+	skeleton, err := d.LabelSkeleton(region.SkeletonID)
+	if err != nil {
+		return err
+	}
+	if skeleton == nil {
+		return fmt.Errorf("%w: the skeleton of this region no longer exists", ErrInvalidRegion)
+	}
+
+	if width, height, err := d.imageSize(region.MediaFileID); err == nil {
+		keypoints = keypoints.Snap(width, height)
+	}
+	if err := keypoints.Validate(skeleton); err != nil {
+		return err
+	}
+
+	box := keypoints.Bounds()
+	_, err = d.conn.Exec(
+		`UPDATE regions SET x = ?, y = ?, width = ?, height = ?, keypoints = ? WHERE id = ?`,
+		box.X, box.Y, box.Width, box.Height, keypoints, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating keypoints of region %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateRegionMask replaces the pixels of a mask region and updates its
+// bounding box.
+func (d *DB) UpdateRegionMask(id int64, mask Mask) error {
+	region, err := d.GetRegion(id)
+	if err != nil {
+		return err
+	}
+	if region == nil || region.Kind != RegionMask {
+		return fmt.Errorf("mask region %d not found", id)
+	}
+
+	width, height, err := d.imageSize(region.MediaFileID)
+	if err != nil {
+		return err
+	}
+	if err := mask.Validate(width, height); err != nil {
+		return err
+	}
+
+	box := mask.Bounds()
+	_, err = d.conn.Exec(
+		`UPDATE regions SET x = ?, y = ?, width = ?, height = ?, mask = ? WHERE id = ?`,
+		box.X, box.Y, box.Width, box.Height, mask, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating mask of region %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateRegionDescription updates a region's description.
+func (d *DB) UpdateRegionDescription(id int64, description string) error {
+	result, err := d.conn.Exec(
+		`UPDATE regions SET description = ? WHERE id = ?`,
+		description, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating description for region %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("region %d not found", id)
+	}
+	return nil
+}
+
+// DeleteRegion removes a region and its labels.
+func (d *DB) DeleteRegion(id int64) error {
+	_, err := d.conn.Exec(`DELETE FROM regions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting region %d: %w", id, err)
+	}
+	return nil
+}
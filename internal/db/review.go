@@ -0,0 +1,161 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidReview is returned for a review of a file that isn't done, a
+// decision that doesn't exist or a rejection without a reason.
+var ErrInvalidReview = errors.New("invalid review")
+
+// Review decisions.
+const (
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// ReviewPending selects, in filters, the files marked done that no
+// reviewer has decided on yet.
+const ReviewPending = "pending"
+
+// Reviews lists the review states a filter can select, in the order shown.
+var Reviews = []string{ReviewPending, ReviewApproved, ReviewRejected}
+
+// ValidateReview checks that a filter's review state is one of Reviews.
+func ValidateReview(review string) error {
+	if !slices.Contains(Reviews, review) {
+		return fmt.Errorf("%w: %q, must be one of %v", ErrInvalidReview, review, Reviews)
+	}
+	return nil
+}
+
+// Review is a reviewer's decision on a media file.
+type Review struct {
+	ID          int64
+	MediaFileID int64
+	Path        string
+	Reviewer    string
+	Decision    string // ReviewApproved or ReviewRejected.
+	Reason      string // Why the file was rejected.
+	CreatedAt   time.Time
+}
+
+// reviewColumns lists the columns read by scanReview, in order.
+const reviewColumns = `r.id, r.media_file_id, m.path, r.reviewer, r.decision, r.reason, r.created_at`
+
+func scanReview(row rowScanner, r *Review) error {
+	return row.Scan(&r.ID, &r.MediaFileID, &r.Path, &r.Reviewer, &r.Decision, &r.Reason, &r.CreatedAt)
+}
+
+// ReviewMediaFile records a reviewer's decision on a file marked done. An
+// approved file stays done; a rejected one goes back to todo, into the
+// annotator's queue, with the reason.
+func (d *DB) ReviewMediaFile(mediaFileID int64, reviewer, decision, reason string) (*Review, error) {
+	reason = strings.TrimSpace(reason)
+	switch decision {
+	case ReviewApproved:
+	case ReviewRejected:
+		if reason == "" {
+			return nil, fmt.Errorf("%w: give a reason for the rejection", ErrInvalidReview)
+		}
+	default:
+		return nil, fmt.Errorf("%w: decision must be %q or %q", ErrInvalidReview, ReviewApproved, ReviewRejected)
+	}
+
+	tx, err := d.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning review: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow(`SELECT status FROM media_files WHERE id = ?`, mediaFileID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("media file %d not found", mediaFileID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching status of media file %d: %w", mediaFileID, err)
+	}
+	if status != StatusDone {
+		return nil, fmt.Errorf("%w: only files marked done can be reviewed", ErrInvalidReview)
+	}
+
+	if decision == ReviewRejected {
+		status = StatusTodo
+	}
+	_, err = tx.Exec(
+		`UPDATE media_files SET review = ?, status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		decision, status, mediaFileID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("updating review of media file %d: %w", mediaFileID, err)
+	}
+
+	result, err := tx.Exec(
+		`INSERT INTO reviews (media_file_id, reviewer, decision, reason) VALUES (?, ?, ?, ?)`,
+		mediaFileID, reviewer, decision, reason,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("recording review of media file %d: %w", mediaFileID, err)
+	}
+	id, _ := result.LastInsertId()
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	r := &Review{}
+	row := d.conn.QueryRow(`SELECT `+reviewColumns+` FROM reviews r JOIN media_files m ON m.id = r.media_file_id WHERE r.id = ?`, id)
+	if err := scanReview(row, r); err != nil {
+		return nil, fmt.Errorf("fetching review %d: %w", id, err)
+	}
+	return r, nil
+}
+
+// LatestReview returns the most recent review of a file, or nil if it was
+// never reviewed.
+func (d *DB) LatestReview(mediaFileID int64) (*Review, error) {
+	r := &Review{}
+	row := d.conn.QueryRow(
+		`SELECT `+reviewColumns+` FROM reviews r JOIN media_files m ON m.id = r.media_file_id
+		 WHERE r.media_file_id = ? ORDER BY r.created_at DESC, r.id DESC LIMIT 1`,
+		mediaFileID,
+	)
+	err := scanReview(row, r)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching latest review of media file %d: %w", mediaFileID, err)
+	}
+	return r, nil
+}
+
+// RecentReviews returns the latest reviews across the project, newest
+// first, at most limit of them.
+func (d *DB) RecentReviews(limit int) ([]Review, error) {
+	rows, err := d.conn.Query(
+		`SELECT `+reviewColumns+` FROM reviews r JOIN media_files m ON m.id = r.media_file_id
+		 ORDER BY r.created_at DESC, r.id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching recent reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []Review
+	for rows.Next() {
+		var r Review
+		if err := scanReview(rows, &r); err != nil {
+			return nil, fmt.Errorf("scanning review: %w", err)
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markers snippet() puts around matched terms, split out into SnippetParts.
+const (
+	matchStart = "\x02"
+	matchEnd   = "\x03"
+)
+
+// SearchResult is a file, keyframe or segment whose description matches a
+// search.
+type SearchResult struct {
+	MediaFileID int64
+	Path        string
+	MediaType   string
+	Slot        string // The description slot that matched, if any.
+	KeyframeID  int64  // 0 unless a keyframe's description matched.
+	SegmentID   int64  // 0 unless a segment's description matched.
+	TimestampMs int64  // Of the keyframe, or the start of the segment.
+	Snippet     []SnippetPart
+}
+
+// SnippetPart is a piece of a search result's description excerpt. Match
+// is set on the pieces that matched the search terms.
+type SnippetPart struct {
+	Text  string
+	Match bool
+}
+
+// SearchDescriptions finds files, description slots, keyframes and
+// segments whose descriptions contain every word of the query, best matches first. Double-quoted words must
+// appear as a phrase, and the query's field terms, such as label:dog,
+// restrict the files searched. The project's form fields type field:
+// terms. It returns at most limit results.
+func (d *DB) SearchDescriptions(query string, fields []FieldDefinition, limit int) ([]SearchResult, error) {
+	q, err := ParseQuery(query, fields)
+	if err != nil {
+		return nil, err
+	}
+	match := q.matchExpression()
+	if match == "" {
+		return nil, nil
+	}
+
+	where, args := q.termConditions()
+	restrict := ""
+	if len(where) > 0 {
+		restrict = " AND " + strings.Join(where, " AND ")
+	}
+
+	var queryArgs []any
+	for range 4 {
+		queryArgs = append(queryArgs, matchStart, matchEnd, match)
+		queryArgs = append(queryArgs, args...)
+	}
+	queryArgs = append(queryArgs, limit)
+
+	rows, err := d.conn.Query(
+		`SELECT m.id, m.path, m.media_type, '', 0, 0, 0,
+			snippet(media_files_fts, 0, ?, ?, '…', 16), bm25(media_files_fts) AS score
+		 FROM media_files_fts JOIN media_files m ON m.id = media_files_fts.rowid
+		 WHERE media_files_fts MATCH ?`+restrict+`
+		 UNION ALL
+		 SELECT m.id, m.path, m.media_type, media_descriptions_fts.name, 0, 0, 0,
+			snippet(media_descriptions_fts, 2, ?, ?, '…', 16), bm25(media_descriptions_fts) AS score
+		 FROM media_descriptions_fts JOIN media_files m ON m.id = media_descriptions_fts.media_file_id
+		 WHERE media_descriptions_fts MATCH ?`+restrict+`
+		 UNION ALL
+		 SELECT m.id, m.path, m.media_type, '', k.id, 0, k.timestamp_ms,
+			snippet(keyframes_fts, 0, ?, ?, '…', 16), bm25(keyframes_fts) AS score
+		 FROM keyframes_fts
+		 JOIN keyframes k ON k.id = keyframes_fts.rowid
+		 JOIN media_files m ON m.id = k.media_file_id
+		 WHERE keyframes_fts MATCH ?`+restrict+`
+		 UNION ALL
+		 SELECT m.id, m.path, m.media_type, '', 0, s.id, s.start_ms,
+			snippet(segments_fts, 0, ?, ?, '…', 16), bm25(segments_fts) AS score
+		 FROM segments_fts
+		 JOIN segments s ON s.id = segments_fts.rowid
+		 JOIN media_files m ON m.id = s.media_file_id
+		 WHERE segments_fts MATCH ?`+restrict+`
+		 ORDER BY score ASC LIMIT ?`,
+		queryArgs...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("searching descriptions for %q: %w", query, err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		var snippet string
+		var score float64
+		if err := rows.Scan(&r.MediaFileID, &r.Path, &r.MediaType, &r.Slot, &r.KeyframeID, &r.SegmentID, &r.TimestampMs, &snippet, &score); err != nil {
+			return nil, fmt.Errorf("scanning search result: %w", err)
+		}
+		r.Snippet = snippetParts(snippet)
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// snippetParts splits a snippet at the match markers.
+func snippetParts(snippet string) []SnippetPart {
+	var parts []SnippetPart
+	for snippet != "" {
+		start := strings.Index(snippet, matchStart)
+		if start == -1 {
+			parts = append(parts, SnippetPart{Text: snippet})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, SnippetPart{Text: snippet[:start]})
+		}
+		snippet = snippet[start+len(matchStart):]
+
+		end := strings.Index(snippet, matchEnd)
+		if end == -1 {
+			end = len(snippet)
+		}
+		parts = append(parts, SnippetPart{Text: snippet[:end], Match: true})
+		snippet = strings.TrimPrefix(snippet[end:], matchEnd)
+	}
+	return parts
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSegment is returned when a segment doesn't end after it starts.
+var ErrInvalidSegment = errors.New("invalid segment")
+
+// Segment is a labeled time range on a video or audio file.
+type Segment struct {
+	ID          int64
+	MediaFileID int64
+	StartMs     int64
+	EndMs       int64
+	Description string
+	Transcript  string // Verbatim speech, separate from the description.
+	Language    string // Language code of the transcript, e.g. "en".
+	Labels      []AppliedLabel
+}
+
+func validateSegmentRange(startMs, endMs int64) error {
+	if startMs < 0 {
+		return fmt.Errorf("%w: segment can't start before 0", ErrInvalidSegment)
+	}
+	if endMs <= startMs {
+		return fmt.Errorf("%w: segment must end after it starts", ErrInvalidSegment)
+	}
+	return nil
+}
+
+// SegmentsForMediaFile returns all segments of a media file, ordered by start time.
+// Each segment includes its labels.
+func (d *DB) SegmentsForMediaFile(mediaFileID int64) ([]Segment, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, media_file_id, start_ms, end_ms, description, transcript, language
+		 FROM segments WHERE media_file_id = ?
+		 ORDER BY start_ms ASC, end_ms ASC`,
+		mediaFileID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching segments for media file %d: %w", mediaFileID, err)
+	}
+	defer rows.Close()
+
+	var segments []Segment
+	for rows.Next() {
+		var seg Segment
+		if err := rows.Scan(&seg.ID, &seg.MediaFileID, &seg.StartMs, &seg.EndMs, &seg.Description, &seg.Transcript, &seg.Language); err != nil {
+			return nil, fmt.Errorf("scanning segment: %w", err)
+		}
+		segments = append(segments, seg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range segments {
+		labels, err := d.AppliedLabels(SegmentLabels, segments[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		segments[i].Labels = labels
+	}
+
+	return segments, nil
+}
+
+// GetSegment returns a single segment by ID.
+func (d *DB) GetSegment(id int64) (*Segment, error) {
+	seg := &Segment{}
+	err := d.conn.QueryRow(
+		`SELECT id, media_file_id, start_ms, end_ms, description, transcript, language FROM segments WHERE id = ?`,
+		id,
+	).Scan(&seg.ID, &seg.MediaFileID, &seg.StartMs, &seg.EndMs, &seg.Description, &seg.Transcript, &seg.Language)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching segment %d: %w", id, err)
+	}
+
+	labels, err := d.AppliedLabels(SegmentLabels, seg.ID)
+	if err != nil {
+		return nil, err
+	}
+	seg.Labels = labels
+
+	return seg, nil
+}
+
+// CreateSegment adds a new segment spanning the given time range.
+func (d *DB) CreateSegment(mediaFileID, startMs, endMs int64) (*Segment, error) {
+	if err := validateSegmentRange(startMs, endMs); err != nil {
+		return nil, err
+	}
+
+	result, err := d.conn.Exec(
+		`INSERT INTO segments (media_file_id, start_ms, end_ms) VALUES (?, ?, ?)`,
+		mediaFileID, startMs, endMs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating segment %d-%dms for media file %d: %w", startMs, endMs, mediaFileID, err)
+	}
+
+	id, _ := result.LastInsertId()
+	return d.GetSegment(id)
+}
+
+// UpdateSegmentRange moves or resizes a segment.
+func (d *DB) UpdateSegmentRange(id, startMs, endMs int64) error {
+	if err := validateSegmentRange(startMs, endMs); err != nil {
+		return err
+	}
+
+	result, err := d.conn.Exec(
+		`UPDATE segments SET start_ms = ?, end_ms = ? WHERE id = ?`,
+		startMs, endMs, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating range of segment %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("segment %d not found", id)
+	}
+	return nil
+}
+
+// UpdateSegmentDescription updates a segment's description.
+func (d *DB) UpdateSegmentDescription(id int64, description string) error {
+	result, err := d.conn.Exec(
+		`UPDATE segments SET description = ? WHERE id = ?`,
+		description, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating description for segment %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("segment %d not found", id)
+	}
+	return nil
+}
+
+// DeleteSegment removes a segment and its labels.
+func (d *DB) DeleteSegment(id int64) error {
+	_, err := d.conn.Exec(`DELETE FROM segments WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting segment %d: %w", id, err)
+	}
+	return nil
+}
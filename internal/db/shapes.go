@@ -0,0 +1,256 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Region kinds.
+const (
+	RegionBox       = "box"
+	RegionPolygon   = "polygon"
+	RegionMask      = "mask"
+	RegionKeypoints = "keypoints"
+)
+
+// Point is a polygon vertex in coordinates normalized to the image size.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Polygon is a closed outline of an image region. It is stored as a JSON
+// array of [x, y] pairs.
+type Polygon []Point
+
+// Validate checks that the polygon has at least three vertices within the
+// image and encloses an area.
+func (p Polygon) Validate() error {
+	if len(p) < 3 {
+		return fmt.Errorf("%w: polygon must have at least 3 vertices", ErrInvalidRegion)
+	}
+	for _, pt := range p {
+		if pt.X < 0 || pt.Y < 0 || pt.X > 1 || pt.Y > 1 {
+			return fmt.Errorf("%w: polygon must lie within the image", ErrInvalidRegion)
+		}
+	}
+	// Snapped vertices on one line can leave a rounding error of an area.
+	if p.Area() < 1e-12 {
+		return fmt.Errorf("%w: polygon must enclose an area", ErrInvalidRegion)
+	}
+	return nil
+}
+
+// Area returns the area the polygon encloses, as a share of the image,
+// using the shoelace formula.
+func (p Polygon) Area() float64 {
+	var sum float64
+	for i, pt := range p {
+		q := p[(i+1)%len(p)]
+		sum += pt.X*q.Y - q.X*pt.Y
+	}
+	return math.Abs(sum) / 2
+}
+
+// Snap moves every vertex onto the pixel grid of an image of the given size
+// and drops vertices that collapse onto their predecessor, as well as
+// closing vertices that repeat the first.
+func (p Polygon) Snap(width, height int) Polygon {
+	snapped := make(Polygon, 0, len(p))
+	for _, pt := range p {
+		s := Point{
+			X: math.Round(pt.X*float64(width)) / float64(width),
+			Y: math.Round(pt.Y*float64(height)) / float64(height),
+		}
+		if len(snapped) > 0 && snapped[len(snapped)-1] == s {
+			continue
+		}
+		snapped = append(snapped, s)
+	}
+	for len(snapped) > 1 && snapped[0] == snapped[len(snapped)-1] {
+		snapped = snapped[:len(snapped)-1]
+	}
+	return snapped
+}
+
+// Bounds returns the smallest box containing the polygon.
+func (p Polygon) Bounds() Box {
+	if len(p) == 0 {
+		return Box{}
+	}
+	minX, minY, maxX, maxY := p[0].X, p[0].Y, p[0].X, p[0].Y
+	for _, pt := range p[1:] {
+		minX, maxX = math.Min(minX, pt.X), math.Max(maxX, pt.X)
+		minY, maxY = math.Min(minY, pt.Y), math.Max(maxY, pt.Y)
+	}
+	return Box{X: minX, Y: minY, Width: maxX - minX, Height: maxY - minY}
+}
+
+// Scan implements sql.Scanner for reading polygons from the database.
+func (p *Polygon) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		*p = nil
+		return nil
+	default:
+		return fmt.Errorf("scanning polygon: unsupported type %T", src)
+	}
+
+	var pairs [][2]float64
+	if err := json.Unmarshal(raw, &pairs); err != nil {
+		return fmt.Errorf("scanning polygon: %w", err)
+	}
+	*p = nil
+	for _, pair := range pairs {
+		*p = append(*p, Point{X: pair[0], Y: pair[1]})
+	}
+	return nil
+}
+
+// Value implements driver.Valuer for writing polygons to the database.
+func (p Polygon) Value() (driver.Value, error) {
+	pairs := make([][2]float64, len(p))
+	for i, pt := range p {
+		pairs[i] = [2]float64{pt.X, pt.Y}
+	}
+	b, err := json.Marshal(pairs)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Mask is a brush-painted image region as an uncompressed run-length
+// encoding over the image's pixel grid. Like COCO RLE, pixels are visited
+// column by column and the counts alternate between background and
+// foreground runs, starting with background.
+type Mask struct {
+	Width  int
+	Height int
+	Counts []int
+}
+
+// maskJSON is the stored form of a mask, matching COCO's RLE layout.
+type maskJSON struct {
+	Size   [2]int `json:"size"` // [height, width]
+	Counts []int  `json:"counts"`
+}
+
+// IsZero reports whether the mask is empty, as it is for boxes and polygons.
+func (m Mask) IsZero() bool {
+	return len(m.Counts) == 0
+}
+
+// Validate checks that the mask covers exactly an image of the given size
+// and paints at least one pixel.
+func (m Mask) Validate(width, height int) error {
+	if m.Width != width || m.Height != height {
+		return fmt.Errorf("%w: mask is %dx%d but the image is %dx%d", ErrInvalidRegion, m.Width, m.Height, width, height)
+	}
+
+	total := 0
+	for _, c := range m.Counts {
+		if c < 0 {
+			return fmt.Errorf("%w: mask counts must not be negative", ErrInvalidRegion)
+		}
+		total += c
+	}
+	if total != width*height {
+		return fmt.Errorf("%w: mask counts must add up to %d pixels", ErrInvalidRegion, width*height)
+	}
+	if m.Area() == 0 {
+		return fmt.Errorf("%w: mask must paint at least one pixel", ErrInvalidRegion)
+	}
+	return nil
+}
+
+// Area returns the number of painted pixels.
+func (m Mask) Area() int {
+	area := 0
+	for i := 1; i < len(m.Counts); i += 2 {
+		area += m.Counts[i]
+	}
+	return area
+}
+
+// Bounds returns the smallest box, in normalized coordinates, containing
+// every painted pixel.
+func (m Mask) Bounds() Box {
+	if m.Height == 0 || m.Width == 0 {
+		return Box{}
+	}
+
+	minX, minY, maxX, maxY := m.Width, m.Height, -1, -1
+	pos := 0
+	for i, c := range m.Counts {
+		start, end := pos, pos+c-1
+		pos += c
+		if i%2 == 0 || c == 0 {
+			continue
+		}
+
+		startCol, endCol := start/m.Height, end/m.Height
+		minX, maxX = min(minX, startCol), max(maxX, endCol)
+		if startCol == endCol {
+			minY, maxY = min(minY, start%m.Height), max(maxY, end%m.Height)
+		} else {
+			minY, maxY = 0, m.Height-1
+		}
+	}
+	if maxX < 0 {
+		return Box{}
+	}
+
+	w, h := float64(m.Width), float64(m.Height)
+	return Box{
+		X:      float64(minX) / w,
+		Y:      float64(minY) / h,
+		Width:  float64(maxX-minX+1) / w,
+		Height: float64(maxY-minY+1) / h,
+	}
+}
+
+// Scan implements sql.Scanner for reading masks from the database.
+func (m *Mask) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+	default:
+		return fmt.Errorf("scanning mask: unsupported type %T", src)
+	}
+
+	*m = Mask{}
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var stored maskJSON
+	if err := json.Unmarshal(raw, &stored); err != nil {
+		return fmt.Errorf("scanning mask: %w", err)
+	}
+	*m = Mask{Width: stored.Size[1], Height: stored.Size[0], Counts: stored.Counts}
+	return nil
+}
+
+// Value implements driver.Valuer for writing masks to the database.
+func (m Mask) Value() (driver.Value, error) {
+	if m.IsZero() {
+		return "", nil
+	}
+	b, err := json.Marshal(maskJSON{Size: [2]int{m.Height, m.Width}, Counts: m.Counts})
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
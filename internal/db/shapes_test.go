@@ -0,0 +1,156 @@
+package db
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestPolygonSnap(t *testing.T) {
+	tests := []struct {
+		name   string
+		points Polygon
+		want   Polygon
+	}{
+		{
+			name:   "rounds to pixels",
+			points: Polygon{{0.12, 0.26}, {0.88, 0.26}, {0.5, 0.74}},
+			want:   Polygon{{0.1, 0.3}, {0.9, 0.3}, {0.5, 0.7}},
+		},
+		{
+			name:   "drops repeated vertices",
+			points: Polygon{{0.1, 0.1}, {0.11, 0.12}, {0.9, 0.1}, {0.5, 0.9}},
+			want:   Polygon{{0.1, 0.1}, {0.9, 0.1}, {0.5, 0.9}},
+		},
+		{
+			name:   "drops closing vertex",
+			points: Polygon{{0.1, 0.1}, {0.9, 0.1}, {0.5, 0.9}, {0.1, 0.1}},
+			want:   Polygon{{0.1, 0.1}, {0.9, 0.1}, {0.5, 0.9}},
+		},
+		{
+			name:   "drops closing vertices that snap onto the first",
+			points: Polygon{{0.1, 0.1}, {0.9, 0.1}, {0.5, 0.9}, {0.12, 0.1}, {0.1, 0.08}},
+			want:   Polygon{{0.1, 0.1}, {0.9, 0.1}, {0.5, 0.9}},
+		},
+		{
+			name:   "collapses to a point",
+			points: Polygon{{0.1, 0.1}, {0.11, 0.1}, {0.1, 0.12}},
+			want:   Polygon{{0.1, 0.1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.points.Snap(10, 10)
+			if !slices.EqualFunc(got, tt.want, closePoints) {
+				t.Errorf("Snap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func closePoints(a, b Point) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func TestPolygonValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  Polygon
+		wantErr bool
+	}{
+		{"triangle", Polygon{{0, 0}, {1, 0}, {0, 1}}, false},
+		{"too few vertices", Polygon{{0, 0}, {1, 1}}, true},
+		{"outside the image", Polygon{{0, 0}, {1.5, 0}, {0, 1}}, true},
+		{"on one line", Polygon{{0, 0}, {0.5, 0.5}, {1, 1}}, true},
+		{"snapped onto one line", Polygon{{0.1, 0.1}, {0.5, 0.12}, {0.9, 0.1}}.Snap(10, 10), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.points.Validate()
+			if tt.wantErr && !errors.Is(err, ErrInvalidRegion) {
+				t.Errorf("Validate() = %v, want ErrInvalidRegion", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestPolygonArea(t *testing.T) {
+	tests := []struct {
+		name   string
+		points Polygon
+		want   float64
+	}{
+		{"unit square", Polygon{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, 1},
+		{"clockwise triangle", Polygon{{0, 0}, {0, 1}, {1, 0}}, 0.5},
+		{"on one line", Polygon{{0, 0}, {0.5, 0.5}, {1, 1}}, 0},
+		{"empty", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.points.Area(); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Area() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	// A 3x2 image, visited column by column:
+	//
+	//	. x x
+	//	. x .
+	mask := Mask{Width: 3, Height: 2, Counts: []int{2, 3, 1}}
+
+	if got := mask.Area(); got != 3 {
+		t.Errorf("Area() = %d, want 3", got)
+	}
+	want := Box{X: 1.0 / 3, Y: 0, Width: 2.0 / 3, Height: 1}
+	if got := mask.Bounds(); got != want {
+		t.Errorf("Bounds() = %+v, want %+v", got, want)
+	}
+	if err := mask.Validate(3, 2); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	value, err := mask.Value()
+	if err != nil {
+		t.Fatalf("Value() = %v", err)
+	}
+	if value != `{"size":[2,3],"counts":[2,3,1]}` {
+		t.Errorf("Value() = %v, want COCO RLE layout", value)
+	}
+	var scanned Mask
+	if err := scanned.Scan(value); err != nil {
+		t.Fatalf("Scan() = %v", err)
+	}
+	if scanned.Width != 3 || scanned.Height != 2 || !slices.Equal(scanned.Counts, mask.Counts) {
+		t.Errorf("Scan() = %+v, want %+v", scanned, mask)
+	}
+}
+
+func TestMaskValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		mask Mask
+	}{
+		{"wrong size", Mask{Width: 2, Height: 2, Counts: []int{0, 4}}},
+		{"too few pixels", Mask{Width: 3, Height: 2, Counts: []int{2, 3}}},
+		{"negative count", Mask{Width: 3, Height: 2, Counts: []int{-1, 7}}},
+		{"nothing painted", Mask{Width: 3, Height: 2, Counts: []int{6}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.mask.Validate(3, 2); !errors.Is(err, ErrInvalidRegion) {
+				t.Errorf("Validate() = %v, want ErrInvalidRegion", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidSkeleton is returned when a skeleton template is malformed.
+var ErrInvalidSkeleton = errors.New("invalid skeleton")
+
+// Keypoint visibility flags, as in COCO.
+const (
+	KeypointUnlabeled = 0 // Not placed.
+	KeypointOccluded  = 1 // Placed but not visible.
+	KeypointVisible   = 2
+)
+
+// Skeleton is a keypoint template of a label: named points and the edges
+// connecting them, e.g. a human pose or a set of facial landmarks. Edges
+// are pairs of indices into Keypoints.
+type Skeleton struct {
+	LabelID   int64
+	Name      string // Name of the label.
+	Keypoints []string
+	Edges     [][2]int
+}
+
+// EdgeNames returns the edges as pairs of keypoint names.
+func (s Skeleton) EdgeNames() [][2]string {
+	names := make([][2]string, len(s.Edges))
+	for i, e := range s.Edges {
+		names[i] = [2]string{s.Keypoints[e[0]], s.Keypoints[e[1]]}
+	}
+	return names
+}
+
+// Keypoint is a placed point in coordinates normalized to the image size.
+type Keypoint struct {
+	X          float64
+	Y          float64
+	Visibility int
+}
+
+// Keypoints are the points of one skeleton instance, in the order of the
+// skeleton's keypoint names. They are stored as a JSON array of [x, y, v].
+type Keypoints []Keypoint
+
+// Validate checks the keypoints against a skeleton.
+func (k Keypoints) Validate(s *Skeleton) error {
+	if len(k) != len(s.Keypoints) {
+		return fmt.Errorf("%w: %s needs %d keypoints, got %d", ErrInvalidRegion, s.Name, len(s.Keypoints), len(k))
+	}
+
+	placed := 0
+	for i, kp := range k {
+		if kp.Visibility < KeypointUnlabeled || kp.Visibility > KeypointVisible {
+			return fmt.Errorf("%w: keypoint %q has an invalid visibility", ErrInvalidRegion, s.Keypoints[i])
+		}
+		if kp.Visibility == KeypointUnlabeled {
+			continue
+		}
+		if kp.X < 0 || kp.Y < 0 || kp.X > 1 || kp.Y > 1 {
+			return fmt.Errorf("%w: keypoint %q must lie within the image", ErrInvalidRegion, s.Keypoints[i])
+		}
+		placed++
+	}
+	if placed == 0 {
+		return fmt.Errorf("%w: at least one keypoint must be placed", ErrInvalidRegion)
+	}
+	return nil
+}
+
+// Snap moves every placed keypoint onto the pixel grid of an image of the
+// given size. Unlabeled keypoints are reset to the origin.
+func (k Keypoints) Snap(width, height int) Keypoints {
+	snapped := make(Keypoints, len(k))
+	for i, kp := range k {
+		if kp.Visibility == KeypointUnlabeled {
+			snapped[i] = Keypoint{}
+			continue
+		}
+		p := Polygon{{X: kp.X, Y: kp.Y}}.Snap(width, height)[0]
+		snapped[i] = Keypoint{X: p.X, Y: p.Y, Visibility: kp.Visibility}
+	}
+	return snapped
+}
+
+// Bounds returns the smallest box containing every placed keypoint.
+func (k Keypoints) Bounds() Box {
+	var placed Polygon
+	for _, kp := range k {
+		if kp.Visibility != KeypointUnlabeled {
+			placed = append(placed, Point{X: kp.X, Y: kp.Y})
+		}
+	}
+	return placed.Bounds()
+}
+
+// Scan implements sql.Scanner for reading keypoints from the database.
+func (k *Keypoints) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	case nil:
+		*k = nil
+		return nil
+	default:
+		return fmt.Errorf("scanning keypoints: unsupported type %T", src)
+	}
+
+	var triples [][3]float64
+	if err := json.Unmarshal(raw, &triples); err != nil {
+		return fmt.Errorf("scanning keypoints: %w", err)
+	}
+	*k = nil
+	for _, t := range triples {
+		*k = append(*k, Keypoint{X: t[0], Y: t[1], Visibility: int(t[2])})
+	}
+	return nil
+}
+
+// Value implements driver.Valuer for writing keypoints to the database.
+func (k Keypoints) Value() (driver.Value, error) {
+	triples := make([][3]float64, len(k))
+	for i, kp := range k {
+		triples[i] = [3]float64{kp.X, kp.Y, float64(kp.Visibility)}
+	}
+	b, err := json.Marshal(triples)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// LabelSkeleton returns the skeleton template of a label, or nil if it has none.
+func (d *DB) LabelSkeleton(labelID int64) (*Skeleton, error) {
+	rows, err := d.conn.Query(
+		`SELECT s.label_id, l.name, s.keypoints, s.edges FROM label_skeletons s
+		 JOIN labels l ON l.id = s.label_id
+		 WHERE s.label_id = ?`,
+		labelID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching skeleton of label %d: %w", labelID, err)
+	}
+
+	skeletons, err := scanSkeletons(rows)
+	if err != nil || len(skeletons) == 0 {
+		return nil, err
+	}
+	return &skeletons[0], nil
+}
+
+// AllSkeletons returns every skeleton template, ordered by label name.
+func (d *DB) AllSkeletons() ([]Skeleton, error) {
+	rows, err := d.conn.Query(
+		`SELECT s.label_id, l.name, s.keypoints, s.edges FROM label_skeletons s
+		 JOIN labels l ON l.id = s.label_id
+		 ORDER BY l.name ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching skeletons: %w", err)
+	}
+	return scanSkeletons(rows)
+}
+
+func scanSkeletons(rows *sql.Rows) ([]Skeleton, error) {
+	defer rows.Close()
+
+	var skeletons []Skeleton
+	for rows.Next() {
+		var s Skeleton
+		var keypoints, edges string
+		if err := rows.Scan(&s.LabelID, &s.Name, &keypoints, &edges); err != nil {
+			return nil, fmt.Errorf("scanning skeleton: %w", err)
+		}
+		if err := json.Unmarshal([]byte(keypoints), &s.Keypoints); err != nil {
+			return nil, fmt.Errorf("decoding keypoints of skeleton %q: %w", s.Name, err)
+		}
+		if err := json.Unmarshal([]byte(edges), &s.Edges); err != nil {
+			return nil, fmt.Errorf("decoding edges of skeleton %q: %w", s.Name, err)
+		}
+		skeletons = append(skeletons, s)
+	}
+	return skeletons, rows.Err()
+}
+
+// SetLabelSkeleton validates and stores the skeleton template of a label,
+// replacing any previous one. Keypoints already placed with the previous
+// template are kept, so changing the number of keypoints of a template in
+// use is refused.
+func (d *DB) SetLabelSkeleton(labelID int64, keypoints []string, edges [][2]int) (*Skeleton, error) {
+	var names []string
+	for _, name := range keypoints {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: keypoint names must not be empty", ErrInvalidSkeleton)
+		}
+		if slices.Contains(names, name) {
+			return nil, fmt.Errorf("%w: keypoint %q is listed twice", ErrInvalidSkeleton, name)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: a skeleton needs at least one keypoint", ErrInvalidSkeleton)
+	}
+
+	cleaned := [][2]int{}
+	for _, e := range edges {
+		if e[0] < 0 || e[1] < 0 || e[0] >= len(names) || e[1] >= len(names) || e[0] == e[1] {
+			return nil, fmt.Errorf("%w: edge %d-%d doesn't connect two keypoints", ErrInvalidSkeleton, e[0], e[1])
+		}
+		if !slices.Contains(cleaned, e) && !slices.Contains(cleaned, [2]int{e[1], e[0]}) {
+			cleaned = append(cleaned, e)
+		}
+	}
+
+	var inUse int
+	if err := d.conn.QueryRow(
+		`SELECT COUNT(*) FROM regions WHERE skeleton_label_id = ? AND json_array_length(keypoints) != ?`,
+		labelID, len(names),
+	).Scan(&inUse); err != nil {
+		return nil, fmt.Errorf("checking use of skeleton of label %d: %w", labelID, err)
+	}
+	if inUse > 0 {
+		return nil, fmt.Errorf("%w: %d placed skeletons have a different number of keypoints", ErrInvalidSkeleton, inUse)
+	}
+
+	encodedKeypoints, _ := json.Marshal(names)
+	encodedEdges, _ := json.Marshal(cleaned)
+	_, err := d.conn.Exec(
+		`INSERT INTO label_skeletons (label_id, keypoints, edges) VALUES (?, ?, ?)
+		 ON CONFLICT (label_id) DO UPDATE SET keypoints = excluded.keypoints, edges = excluded.edges`,
+		labelID, string(encodedKeypoints), string(encodedEdges),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("saving skeleton of label %d: %w", labelID, err)
+	}
+
+	return d.LabelSkeleton(labelID)
+}
+
+// DeleteLabelSkeleton removes the skeleton template of a label. A template
+// that has been placed on an image can't be removed.
+func (d *DB) DeleteLabelSkeleton(labelID int64) error {
+	var inUse int
+	if err := d.conn.QueryRow(
+		`SELECT COUNT(*) FROM regions WHERE skeleton_label_id = ?`, labelID,
+	).Scan(&inUse); err != nil {
+		return fmt.Errorf("checking use of skeleton of label %d: %w", labelID, err)
+	}
+	if inUse > 0 {
+		return fmt.Errorf("%w: the skeleton has been placed %d times", ErrInvalidSkeleton, inUse)
+	}
+
+	_, err := d.conn.Exec(`DELETE FROM label_skeletons WHERE label_id = ?`, labelID)
+	if err != nil {
+		return fmt.Errorf("deleting skeleton of label %d: %w", labelID, err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidSpan is returned when a span doesn't cover any characters.
+var ErrInvalidSpan = errors.New("invalid span")
+
+// Span is a labeled character range of a text file. Offsets count Unicode
+// code points from the start of the file; the end is exclusive.
+type Span struct {
+	ID          int64
+	MediaFileID int64
+	StartOffset int
+	EndOffset   int
+	Description string
+	Labels      []AppliedLabel
+}
+
+// SpansForMediaFile returns all spans of a text file, ordered by position.
+// Each span includes its labels.
+func (d *DB) SpansForMediaFile(mediaFileID int64) ([]Span, error) {
+	rows, err := d.conn.Query(
+		`SELECT id, media_file_id, start_offset, end_offset, description
+		 FROM spans WHERE media_file_id = ?
+		 ORDER BY start_offset ASC, end_offset ASC`,
+		mediaFileID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching spans for media file %d: %w", mediaFileID, err)
+	}
+	defer rows.Close()
+
+	var spans []Span
+	for rows.Next() {
+		var span Span
+		if err := rows.Scan(&span.ID, &span.MediaFileID, &span.StartOffset, &span.EndOffset, &span.Description); err != nil {
+			return nil, fmt.Errorf("scanning span: %w", err)
+		}
+		spans = append(spans, span)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range spans {
+		labels, err := d.AppliedLabels(SpanLabels, spans[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		spans[i].Labels = labels
+	}
+
+	return spans, nil
+}
+
+// GetSpan returns a single span by ID.
+func (d *DB) GetSpan(id int64) (*Span, error) {
+	span := &Span{}
+	err := d.conn.QueryRow(
+		`SELECT id, media_file_id, start_offset, end_offset, description FROM spans WHERE id = ?`,
+		id,
+	).Scan(&span.ID, &span.MediaFileID, &span.StartOffset, &span.EndOffset, &span.Description)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching span %d: %w", id, err)
+	}
+
+	if span.Labels, err = d.AppliedLabels(SpanLabels, span.ID); err != nil {
+		return nil, err
+	}
+	return span, nil
+}
+
+// CreateSpan adds a new span covering the given character range.
+func (d *DB) CreateSpan(mediaFileID int64, startOffset, endOffset int) (*Span, error) {
+	if startOffset < 0 {
+		return nil, fmt.Errorf("%w: span can't start before the text", ErrInvalidSpan)
+	}
+	if endOffset <= startOffset {
+		return nil, fmt.Errorf("%w: span must end after it starts", ErrInvalidSpan)
+	}
+
+	result, err := d.conn.Exec(
+		`INSERT INTO spans (media_file_id, start_offset, end_offset) VALUES (?, ?, ?)`,
+		mediaFileID, startOffset, endOffset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating span %d-%d for media file %d: %w", startOffset, endOffset, mediaFileID, err)
+	}
+
+	id, _ := result.LastInsertId()
+	return d.GetSpan(id)
+}
+
+// UpdateSpanDescription updates a span's description.
+func (d *DB) UpdateSpanDescription(id int64, description string) error {
+	result, err := d.conn.Exec(
+		`UPDATE spans SET description = ? WHERE id = ?`,
+		description, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating description for span %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("span %d not found", id)
+	}
+	return nil
+}
+
+// DeleteSpan removes a span and its labels.
+func (d *DB) DeleteSpan(id int64) error {
+	_, err := d.conn.Exec(`DELETE FROM spans WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting span %d: %w", id, err)
+	}
+	return nil
+}
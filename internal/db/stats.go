@@ -0,0 +1,292 @@
+package db
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Stats summarizes the labeling state of a project.
+type Stats struct {
+	TotalFiles       int
+	UnlabeledFiles   int
+	UndescribedFiles int
+	Labels           []LabelStats
+	MediaTypes       []MediaTypeStats
+	Cooccurrence     []LabelPair
+	Directories      []DirectoryStats
+	Progress         *Progress
+}
+
+// LabelStats counts how often a label is used. Files and Keyframes count
+// positive assignments; NegatedFiles counts files where the label was
+// asserted absent.
+type LabelStats struct {
+	Label        Label
+	Files        int
+	Keyframes    int
+	NegatedFiles int
+}
+
+// MediaTypeStats counts how many files of a media type carry at least one label.
+type MediaTypeStats struct {
+	MediaType string
+	Files     int
+	Labeled   int
+}
+
+// LabelPair counts the files that carry both labels. A is ordered before B by name.
+type LabelPair struct {
+	A     string
+	B     string
+	Files int
+}
+
+// DirectoryStats counts label usage among the files of one directory.
+// Files directly in the project root are reported under ".".
+type DirectoryStats struct {
+	Directory string
+	Files     int
+	Labels    map[string]int
+}
+
+// UnlabeledPercent returns the share of files without labels, from 0 to 100.
+func (s *Stats) UnlabeledPercent() float64 {
+	return percent(s.UnlabeledFiles, s.TotalFiles)
+}
+
+// UndescribedPercent returns the share of files without a description, from 0 to 100.
+func (s *Stats) UndescribedPercent() float64 {
+	return percent(s.UndescribedFiles, s.TotalFiles)
+}
+
+// Coverage returns the share of files of this type that carry a label, from 0 to 100.
+func (m MediaTypeStats) Coverage() float64 {
+	return percent(m.Labeled, m.Files)
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+// Stats computes label counts, coverage, co-occurrence, the distribution
+// of labels across directories and workflow progress, over the media files
+// matching the filter.
+func (d *DB) Stats(filter FileFilter) (*Stats, error) {
+	s := &Stats{}
+
+	where, args := filter.conditions()
+	err := d.conn.QueryRow(
+		`SELECT COUNT(*),
+			COALESCE(SUM(NOT EXISTS (SELECT 1 FROM media_labels ml WHERE ml.media_file_id = m.id AND NOT ml.negated)), 0),
+			COALESCE(SUM(TRIM(m.description) = ''), 0)
+		 FROM media_files m
+		 WHERE `+where,
+		args...,
+	).Scan(&s.TotalFiles, &s.UnlabeledFiles, &s.UndescribedFiles)
+	if err != nil {
+		return nil, fmt.Errorf("counting media files: %w", err)
+	}
+
+	if s.Labels, err = d.labelStats(filter); err != nil {
+		return nil, err
+	}
+	if s.MediaTypes, err = d.mediaTypeStats(filter); err != nil {
+		return nil, err
+	}
+	if s.Cooccurrence, err = d.labelPairs(filter); err != nil {
+		return nil, err
+	}
+	if s.Directories, err = d.directoryStats(filter); err != nil {
+		return nil, err
+	}
+	if s.Progress, err = d.Progress(filter); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (d *DB) labelStats(filter FileFilter) ([]LabelStats, error) {
+	where, args := filter.conditions()
+	rows, err := d.conn.Query(
+		`SELECT `+labelColumns+`,
+			(SELECT COUNT(*) FROM media_labels ml JOIN media_files m ON m.id = ml.media_file_id
+				WHERE ml.label_id = l.id AND NOT ml.negated AND `+where+`),
+			(SELECT COUNT(*) FROM keyframe_labels kl
+				JOIN keyframes k ON k.id = kl.keyframe_id
+				JOIN media_files m ON m.id = k.media_file_id
+				WHERE kl.label_id = l.id AND NOT kl.negated AND `+where+`),
+			(SELECT COUNT(*) FROM media_labels ml JOIN media_files m ON m.id = ml.media_file_id
+				WHERE ml.label_id = l.id AND ml.negated AND `+where+`)
+		 FROM labels l
+		 ORDER BY 6 DESC, 7 DESC, l.name ASC`,
+		slices.Concat(args, args, args)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("counting labels: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []LabelStats
+	for rows.Next() {
+		var ls LabelStats
+		l := &ls.Label
+		if err := rows.Scan(&l.ID, &l.Name, &l.Definition, &l.PositiveNotes, &l.NegativeNotes, &ls.Files, &ls.Keyframes, &ls.NegatedFiles); err != nil {
+			return nil, fmt.Errorf("scanning label counts: %w", err)
+		}
+		stats = append(stats, ls)
+	}
+	return stats, rows.Err()
+}
+
+func (d *DB) mediaTypeStats(filter FileFilter) ([]MediaTypeStats, error) {
+	where, args := filter.conditions()
+	rows, err := d.conn.Query(
+		`SELECT m.media_type, COUNT(*),
+			SUM(EXISTS (SELECT 1 FROM media_labels ml WHERE ml.media_file_id = m.id AND NOT ml.negated))
+		 FROM media_files m
+		 WHERE `+where+`
+		 GROUP BY m.media_type
+		 ORDER BY m.media_type ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("counting media types: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []MediaTypeStats
+	for rows.Next() {
+		var ms MediaTypeStats
+		if err := rows.Scan(&ms.MediaType, &ms.Files, &ms.Labeled); err != nil {
+			return nil, fmt.Errorf("scanning media type counts: %w", err)
+		}
+		stats = append(stats, ms)
+	}
+	return stats, rows.Err()
+}
+
+func (d *DB) labelPairs(filter FileFilter) ([]LabelPair, error) {
+	where, args := filter.conditions()
+	rows, err := d.conn.Query(
+		`SELECT la.name, lb.name, COUNT(*) FROM media_labels a
+		 JOIN media_labels b ON b.media_file_id = a.media_file_id
+		 JOIN labels la ON la.id = a.label_id
+		 JOIN labels lb ON lb.id = b.label_id
+		 JOIN media_files m ON m.id = a.media_file_id
+		 WHERE la.name < lb.name AND NOT a.negated AND NOT b.negated AND `+where+`
+		 GROUP BY la.id, lb.id
+		 ORDER BY 3 DESC, la.name ASC, lb.name ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("counting label co-occurrence: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []LabelPair
+	for rows.Next() {
+		var p LabelPair
+		if err := rows.Scan(&p.A, &p.B, &p.Files); err != nil {
+			return nil, fmt.Errorf("scanning label pair: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+func (d *DB) directoryStats(filter FileFilter) ([]DirectoryStats, error) {
+	where, args := filter.conditions()
+	rows, err := d.conn.Query(
+		`SELECT m.path, l.name FROM media_files m
+		 LEFT JOIN media_labels ml ON ml.media_file_id = m.id AND NOT ml.negated
+		 LEFT JOIN labels l ON l.id = ml.label_id
+		 WHERE `+where+`
+		 ORDER BY m.path ASC`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching labels by directory: %w", err)
+	}
+	defer rows.Close()
+
+	byDir := make(map[string]*DirectoryStats)
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var filePath string
+		var label *string
+		if err := rows.Scan(&filePath, &label); err != nil {
+			return nil, fmt.Errorf("scanning labels by directory: %w", err)
+		}
+
+		dir := path.Dir(strings.ReplaceAll(filePath, "\\", "/"))
+		ds, ok := byDir[dir]
+		if !ok {
+			ds = &DirectoryStats{Directory: dir, Labels: make(map[string]int)}
+			byDir[dir] = ds
+		}
+		if !seen[filePath] {
+			seen[filePath] = true
+			ds.Files++
+		}
+		if label != nil {
+			ds.Labels[*label]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	stats := make([]DirectoryStats, 0, len(byDir))
+	for _, ds := range byDir {
+		stats = append(stats, *ds)
+	}
+	slices.SortFunc(stats, func(a, b DirectoryStats) int {
+		return strings.Compare(a.Directory, b.Directory)
+	})
+	return stats, nil
+}
+
+// Matrix is a square table of label co-occurrence counts. Counts[i][j] is the
+// number of files carrying both Labels[i] and Labels[j]; the diagonal holds
+// each label's own file count.
+type Matrix struct {
+	Labels []string
+	Counts [][]int
+}
+
+// CooccurrenceMatrix builds a co-occurrence matrix of the most used labels,
+// limited to at most limit labels.
+func (s *Stats) CooccurrenceMatrix(limit int) Matrix {
+	var names []string
+	var files []int
+	for _, ls := range s.Labels {
+		if ls.Files > 0 && len(names) < limit {
+			names = append(names, ls.Label.Name)
+			files = append(files, ls.Files)
+		}
+	}
+
+	m := Matrix{Labels: names, Counts: make([][]int, len(names))}
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+		m.Counts[i] = make([]int, len(names))
+		m.Counts[i][i] = files[i]
+	}
+
+	for _, p := range s.Cooccurrence {
+		i, okA := index[p.A]
+		j, okB := index[p.B]
+		if okA && okB {
+			m.Counts[i][j] = p.Files
+			m.Counts[j][i] = p.Files
+		}
+	}
+	return m
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrInvalidStatus is returned for a workflow status that doesn't exist.
+var ErrInvalidStatus = errors.New("invalid status")
+
+// Workflow statuses of a media file.
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusDone       = "done"
+	StatusSkipped    = "skipped"
+	StatusFlagged    = "flagged"
+)
+
+// Statuses lists every workflow status in the order they're shown.
+var Statuses = []string{StatusTodo, StatusInProgress, StatusDone, StatusSkipped, StatusFlagged}
+
+// ValidateStatus checks that a status is one of Statuses.
+func ValidateStatus(status string) error {
+	if !slices.Contains(Statuses, status) {
+		return fmt.Errorf("%w: %q, must be one of %v", ErrInvalidStatus, status, Statuses)
+	}
+	return nil
+}
+
+// SetStatus changes the workflow status of a media file. Marking a file
+// done, or taking it out of done, clears its review: a rejected file goes
+// back to review once it's done again, and an approved one that's being
+// reworked is no longer approved.
+func (d *DB) SetStatus(id int64, status string) error {
+	if err := ValidateStatus(status); err != nil {
+		return err
+	}
+
+	result, err := d.conn.Exec(
+		`UPDATE media_files SET
+			review = CASE WHEN (status = 'done') <> (? = 'done') THEN '' ELSE review END,
+			status = ?,
+			updated_at = CURRENT_TIMESTAMP
+		 WHERE id = ?`,
+		status, status, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating status of media file %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("media file %d not found", id)
+	}
+	return nil
+}
+
+// Progress counts the files of a project by workflow status.
+type Progress struct {
+	Total    int
+	ByStatus map[string]int
+}
+
+// Finished returns how many files need no more work: done or skipped.
+func (p *Progress) Finished() int {
+	return p.ByStatus[StatusDone] + p.ByStatus[StatusSkipped]
+}
+
+// FinishedPercent returns the share of finished files, from 0 to 100.
+func (p *Progress) FinishedPercent() float64 {
+	return percent(p.Finished(), p.Total)
+}
+
+// Progress counts the media files matching the filter by workflow status.
+func (d *DB) Progress(filter FileFilter) (*Progress, error) {
+	where, args := filter.conditions()
+	rows, err := d.conn.Query(`SELECT m.status, COUNT(*) FROM media_files m WHERE `+where+` GROUP BY m.status`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("counting media files by status: %w", err)
+	}
+	defer rows.Close()
+
+	p := &Progress{ByStatus: make(map[string]int)}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("scanning status count: %w", err)
+		}
+		p.ByStatus[status] = count
+		p.Total += count
+	}
+	return p, rows.Err()
+}
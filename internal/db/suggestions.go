@@ -0,0 +1,239 @@
+package db
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuggestions is the number of labels returned by SearchLabels.
+const maxSuggestions = 10
+
+// SearchOptions carries the context used to rank label suggestions.
+type SearchOptions struct {
+	// FileID is the media file being labeled. Labels that often appear
+	// together with the labels already on it are ranked higher.
+	FileID int64
+	// PrevFileID is the file labeled before this one. Its labels are
+	// suggested when the query is empty.
+	PrevFileID int64
+	// Recent lists labels used in this session, most recent first.
+	Recent []int64
+}
+
+// labelCandidate is a label along with the signals used to rank it.
+type labelCandidate struct {
+	Label
+	uses  int
+	score float64
+}
+
+// SearchLabels returns up to 10 labels matching a query. Labels match by prefix,
+// substring or as a fuzzy subsequence, and are ranked by match quality, global
+// usage, recent use in this session and co-occurrence with the labels on the
+// current file. An empty query suggests the labels of the previous file.
+func (d *DB) SearchLabels(query string, opts SearchOptions) ([]Label, error) {
+	candidates, err := d.labelCandidates()
+	if err != nil {
+		return nil, err
+	}
+
+	cooccurrence, err := d.labelCooccurrence(opts.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var include func(c labelCandidate) (float64, bool)
+	if query == "" {
+		// Labels already on the file, either way, need no suggestion; of
+		// the previous file's, only those that apply carry over.
+		current, err := d.labelIDsForMediaFile(opts.FileID, true)
+		if err != nil {
+			return nil, err
+		}
+		previous, err := d.labelIDsForMediaFile(opts.PrevFileID, false)
+		if err != nil {
+			return nil, err
+		}
+
+		include = func(c labelCandidate) (float64, bool) {
+			if slices.Contains(current, c.ID) {
+				return 0, false
+			}
+			if slices.Contains(previous, c.ID) {
+				return 50, true
+			}
+			if slices.Contains(opts.Recent, c.ID) {
+				return 0, true
+			}
+			return 0, false
+		}
+	} else {
+		include = func(c labelCandidate) (float64, bool) {
+			return matchScore(strings.ToLower(c.Name), query)
+		}
+	}
+
+	maxCooccurrence := 0
+	for _, n := range cooccurrence {
+		maxCooccurrence = max(maxCooccurrence, n)
+	}
+
+	var ranked []labelCandidate
+	for _, c := range candidates {
+		score, ok := include(c)
+		if !ok {
+			continue
+		}
+
+		score += 10 * math.Log1p(float64(c.uses))
+		if i := slices.Index(opts.Recent, c.ID); i >= 0 {
+			score += max(0, 25-2*float64(i))
+		}
+		if maxCooccurrence > 0 {
+			score += 15 * float64(cooccurrence[c.ID]) / float64(maxCooccurrence)
+		}
+
+		c.score = score
+		ranked = append(ranked, c)
+	}
+
+	slices.SortStableFunc(ranked, func(a, b labelCandidate) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	labels := make([]Label, 0, min(len(ranked), maxSuggestions))
+	for _, c := range ranked[:min(len(ranked), maxSuggestions)] {
+		labels = append(labels, c.Label)
+	}
+	return labels, nil
+}
+
+// matchScore rates how well a label name matches a query. Both must be lowercase.
+func matchScore(name, query string) (float64, bool) {
+	switch {
+	case name == query:
+		return 100, true
+	case strings.HasPrefix(name, query):
+		return 60, true
+	}
+
+	if idx := strings.Index(name, query); idx >= 0 {
+		// Matches at a word boundary, like "terr" in "yorkshire_terrier", rank above mid-word ones.
+		if strings.ContainsRune(" _-/.:", rune(name[idx-1])) {
+			return 45, true
+		}
+		return 30, true
+	}
+
+	// Fuzzy: every query character appears in order. Runs of adjacent
+	// characters score higher than scattered ones.
+	score := 10.0
+	pos := 0
+	last := -2
+	for _, r := range query {
+		idx := strings.IndexRune(name[pos:], r)
+		if idx < 0 {
+			return 0, false
+		}
+		at := pos + idx
+		if at == last+1 {
+			score += 2
+		}
+		last = at
+		pos = at + utf8.RuneLen(r)
+	}
+	return score, true
+}
+
+// labelCandidates returns every label with its number of uses across files and keyframes.
+func (d *DB) labelCandidates() ([]labelCandidate, error) {
+	rows, err := d.conn.Query(
+		`SELECT ` + labelColumns + `,
+			(SELECT COUNT(*) FROM media_labels ml WHERE ml.label_id = l.id) +
+			(SELECT COUNT(*) FROM keyframe_labels kl WHERE kl.label_id = l.id)
+		 FROM labels l`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching label usage: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []labelCandidate
+	for rows.Next() {
+		var c labelCandidate
+		if err := rows.Scan(&c.ID, &c.Name, &c.Definition, &c.PositiveNotes, &c.NegativeNotes, &c.uses); err != nil {
+			return nil, fmt.Errorf("scanning label usage: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// labelCooccurrence counts, per label, how many files it shares with the labels on a media file.
+func (d *DB) labelCooccurrence(mediaFileID int64) (map[int64]int, error) {
+	counts := make(map[int64]int)
+	if mediaFileID == 0 {
+		return counts, nil
+	}
+
+	rows, err := d.conn.Query(
+		`SELECT other.label_id, COUNT(*) FROM media_labels current
+		 JOIN media_labels peer ON peer.label_id = current.label_id AND peer.media_file_id != current.media_file_id AND NOT peer.negated
+		 JOIN media_labels other ON other.media_file_id = peer.media_file_id AND other.label_id != current.label_id AND NOT other.negated
+		 WHERE current.media_file_id = ? AND NOT current.negated
+		 GROUP BY other.label_id`,
+		mediaFileID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching label co-occurrence for media file %d: %w", mediaFileID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("scanning label co-occurrence: %w", err)
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+// labelIDsForMediaFile returns the IDs of the labels on a media file,
+// leaving out negated ones unless withNegated is set.
+func (d *DB) labelIDsForMediaFile(mediaFileID int64, withNegated bool) ([]int64, error) {
+	if mediaFileID == 0 {
+		return nil, nil
+	}
+
+	rows, err := d.conn.Query(
+		`SELECT label_id FROM media_labels WHERE media_file_id = ? AND (? OR NOT negated)`,
+		mediaFileID, withNegated,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching label IDs for media file %d: %w", mediaFileID, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning label ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,214 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Track follows one object across a video. Its boxes are set at some
+// timestamps and linearly interpolated in between; before the first and
+// after the last box the object isn't in view.
+type Track struct {
+	ID          int64
+	MediaFileID int64
+	LabelID     int64
+	Label       string // Name of the label.
+	Name        string // Optional identity, e.g. "red car".
+	Boxes       []TrackBox
+}
+
+// TrackBox is the box of a track at a point in time, in coordinates
+// normalized to the video frame.
+type TrackBox struct {
+	TimestampMs int64
+	Box
+}
+
+// BoxAt returns the track's box at a timestamp, interpolating between the
+// surrounding boxes. It reports false outside the track's time span.
+func (t Track) BoxAt(ms int64) (Box, bool) {
+	for i, b := range t.Boxes {
+		if b.TimestampMs == ms {
+			return b.Box, true
+		}
+		if b.TimestampMs < ms {
+			continue
+		}
+		if i == 0 {
+			return Box{}, false
+		}
+
+		prev := t.Boxes[i-1]
+		f := float64(ms-prev.TimestampMs) / float64(b.TimestampMs-prev.TimestampMs)
+		lerp := func(a, b float64) float64 { return a + (b-a)*f }
+		return Box{
+			X:      lerp(prev.X, b.X),
+			Y:      lerp(prev.Y, b.Y),
+			Width:  lerp(prev.Width, b.Width),
+			Height: lerp(prev.Height, b.Height),
+		}, true
+	}
+	return Box{}, false
+}
+
+// Span returns the timestamps of the track's first and last box.
+func (t Track) Span() (startMs, endMs int64) {
+	if len(t.Boxes) == 0 {
+		return 0, 0
+	}
+	return t.Boxes[0].TimestampMs, t.Boxes[len(t.Boxes)-1].TimestampMs
+}
+
+// TracksForMediaFile returns all tracks of a video with their boxes,
+// ordered by creation.
+func (d *DB) TracksForMediaFile(mediaFileID int64) ([]Track, error) {
+	rows, err := d.conn.Query(
+		`SELECT t.id, t.media_file_id, t.label_id, l.name, t.name FROM tracks t
+		 JOIN labels l ON l.id = t.label_id
+		 WHERE t.media_file_id = ?
+		 ORDER BY t.id ASC`,
+		mediaFileID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching tracks for media file %d: %w", mediaFileID, err)
+	}
+	defer rows.Close()
+
+	var tracks []Track
+	for rows.Next() {
+		var t Track
+		if err := rows.Scan(&t.ID, &t.MediaFileID, &t.LabelID, &t.Label, &t.Name); err != nil {
+			return nil, fmt.Errorf("scanning track: %w", err)
+		}
+		tracks = append(tracks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range tracks {
+		boxes, err := d.trackBoxes(tracks[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		tracks[i].Boxes = boxes
+	}
+
+	return tracks, nil
+}
+
+// GetTrack returns a single track by ID.
+func (d *DB) GetTrack(id int64) (*Track, error) {
+	t := &Track{}
+	err := d.conn.QueryRow(
+		`SELECT t.id, t.media_file_id, t.label_id, l.name, t.name FROM tracks t
+		 JOIN labels l ON l.id = t.label_id
+		 WHERE t.id = ?`,
+		id,
+	).Scan(&t.ID, &t.MediaFileID, &t.LabelID, &t.Label, &t.Name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching track %d: %w", id, err)
+	}
+
+	if t.Boxes, err = d.trackBoxes(t.ID); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (d *DB) trackBoxes(trackID int64) ([]TrackBox, error) {
+	rows, err := d.conn.Query(
+		`SELECT timestamp_ms, x, y, width, height FROM track_boxes
+		 WHERE track_id = ? ORDER BY timestamp_ms ASC`,
+		trackID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("fetching boxes of track %d: %w", trackID, err)
+	}
+	defer rows.Close()
+
+	boxes := []TrackBox{}
+	for rows.Next() {
+		var b TrackBox
+		if err := rows.Scan(&b.TimestampMs, &b.X, &b.Y, &b.Width, &b.Height); err != nil {
+			return nil, fmt.Errorf("scanning track box: %w", err)
+		}
+		boxes = append(boxes, b)
+	}
+	return boxes, rows.Err()
+}
+
+// CreateTrack adds a new track, without boxes, to a video.
+func (d *DB) CreateTrack(mediaFileID, labelID int64, name string) (*Track, error) {
+	result, err := d.conn.Exec(
+		`INSERT INTO tracks (media_file_id, label_id, name) VALUES (?, ?, ?)`,
+		mediaFileID, labelID, name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating track for media file %d: %w", mediaFileID, err)
+	}
+
+	id, _ := result.LastInsertId()
+	return d.GetTrack(id)
+}
+
+// UpdateTrack changes the label and name of a track.
+func (d *DB) UpdateTrack(id, labelID int64, name string) error {
+	result, err := d.conn.Exec(
+		`UPDATE tracks SET label_id = ?, name = ? WHERE id = ?`,
+		labelID, name, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating track %d: %w", id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("track %d not found", id)
+	}
+	return nil
+}
+
+// DeleteTrack removes a track and its boxes.
+func (d *DB) DeleteTrack(id int64) error {
+	_, err := d.conn.Exec(`DELETE FROM tracks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("deleting track %d: %w", id, err)
+	}
+	return nil
+}
+
+// SetTrackBox sets the box of a track at a timestamp, replacing any box
+// already set there.
+func (d *DB) SetTrackBox(trackID, timestampMs int64, box Box) error {
+	if err := box.Validate(); err != nil {
+		return err
+	}
+	if timestampMs < 0 {
+		return fmt.Errorf("%w: timestamp can't be negative", ErrInvalidRegion)
+	}
+
+	_, err := d.conn.Exec(
+		`INSERT INTO track_boxes (track_id, timestamp_ms, x, y, width, height) VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT DO UPDATE SET x = excluded.x, y = excluded.y, width = excluded.width, height = excluded.height`,
+		trackID, timestampMs, box.X, box.Y, box.Width, box.Height,
+	)
+	if err != nil {
+		return fmt.Errorf("setting box of track %d at %dms: %w", trackID, timestampMs, err)
+	}
+	return nil
+}
+
+// DeleteTrackBox removes the box of a track at a timestamp.
+func (d *DB) DeleteTrackBox(trackID, timestampMs int64) error {
+	_, err := d.conn.Exec(
+		`DELETE FROM track_boxes WHERE track_id = ? AND timestamp_ms = ?`,
+		trackID, timestampMs,
+	)
+	if err != nil {
+		return fmt.Errorf("deleting box of track %d at %dms: %w", trackID, timestampMs, err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrackBoxAt(t *testing.T) {
+	track := Track{Boxes: []TrackBox{
+		{TimestampMs: 1000, Box: Box{X: 0.1, Y: 0.1, Width: 0.2, Height: 0.2}},
+		{TimestampMs: 2000, Box: Box{X: 0.5, Y: 0.3, Width: 0.4, Height: 0.2}},
+		{TimestampMs: 4000, Box: Box{X: 0.5, Y: 0.5, Width: 0.4, Height: 0.4}},
+	}}
+
+	tests := []struct {
+		name   string
+		ms     int64
+		want   Box
+		wantOK bool
+	}{
+		{"before the first box", 999, Box{}, false},
+		{"at the first box", 1000, Box{X: 0.1, Y: 0.1, Width: 0.2, Height: 0.2}, true},
+		{"halfway", 1500, Box{X: 0.3, Y: 0.2, Width: 0.3, Height: 0.2}, true},
+		{"a quarter into a longer gap", 2500, Box{X: 0.5, Y: 0.35, Width: 0.4, Height: 0.25}, true},
+		{"at the last box", 4000, Box{X: 0.5, Y: 0.5, Width: 0.4, Height: 0.4}, true},
+		{"after the last box", 4001, Box{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := track.BoxAt(tt.ms)
+			if ok != tt.wantOK || !closeBoxes(got, tt.want) {
+				t.Errorf("BoxAt(%d) = %+v, %v, want %+v, %v", tt.ms, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTrackBoxAtWithoutBoxes(t *testing.T) {
+	if _, ok := (Track{}).BoxAt(0); ok {
+		t.Error("BoxAt() on a track without boxes reported a box")
+	}
+}
+
+func closeBoxes(a, b Box) bool {
+	const eps = 1e-9
+	return math.Abs(a.X-b.X) < eps && math.Abs(a.Y-b.Y) < eps &&
+		math.Abs(a.Width-b.Width) < eps && math.Abs(a.Height-b.Height) < eps
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidLanguage is returned when a transcript's language isn't a
+// language code like "en" or "pt-BR".
+var ErrInvalidLanguage = errors.New("invalid language code")
+
+var languagePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// TranscriptUpdate holds the transcript fields to change on a keyframe or
+// segment. Nil fields are left untouched.
+type TranscriptUpdate struct {
+	Transcript *string
+	Language   *string
+}
+
+func (u TranscriptUpdate) validate() error {
+	if u.Language == nil {
+		return nil
+	}
+	*u.Language = strings.TrimSpace(*u.Language)
+	if *u.Language != "" && !languagePattern.MatchString(*u.Language) {
+		return fmt.Errorf("%w: %q", ErrInvalidLanguage, *u.Language)
+	}
+	return nil
+}
+
+// UpdateKeyframeTranscript updates the transcript or language of a keyframe.
+func (d *DB) UpdateKeyframeTranscript(id int64, update TranscriptUpdate) error {
+	return d.updateTranscript("keyframes", "keyframe", id, update)
+}
+
+// UpdateSegmentTranscript updates the transcript or language of a segment.
+func (d *DB) UpdateSegmentTranscript(id int64, update TranscriptUpdate) error {
+	return d.updateTranscript("segments", "segment", id, update)
+}
+
+func (d *DB) updateTranscript(table, kind string, id int64, update TranscriptUpdate) error {
+	if err := update.validate(); err != nil {
+		return err
+	}
+
+	result, err := d.conn.Exec(
+		`UPDATE `+table+` SET
+			transcript = COALESCE(?, transcript),
+			language = COALESCE(?, language)
+		 WHERE id = ?`,
+		update.Transcript, update.Language, id,
+	)
+	if err != nil {
+		return fmt.Errorf("updating transcript for %s %d: %w", kind, id, err)
+	}
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("%s %d not found", kind, id)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"encoding/json"
+	"log"
+	"path/filepath"
+	"slices"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+type cocoImage struct {
+	ID       int    `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type cocoAnnotation struct {
+	ID           int        `json:"id"`
+	ImageID      int        `json:"image_id"`
+	CategoryID   int        `json:"category_id"`
+	BBox         [4]float64 `json:"bbox"`
+	Area         float64    `json:"area"`
+	Segmentation any        `json:"segmentation,omitempty"`
+	Keypoints    []float64  `json:"keypoints,omitempty"`
+	NumKeypoints int        `json:"num_keypoints,omitempty"`
+	IsCrowd      int        `json:"iscrowd"`
+	// Attributes of the region's label, in the CVAT style.
+	Attributes db.Attributes `json:"attributes,omitempty"`
+}
+
+// cocoRLE is an uncompressed COCO run-length encoding.
+type cocoRLE struct {
+	Size   [2]int `json:"size"` // [height, width]
+	Counts []int  `json:"counts"`
+}
+
+func newCOCORLE(m db.Mask) *cocoRLE {
+	return &cocoRLE{Size: [2]int{m.Height, m.Width}, Counts: m.Counts}
+}
+
+type cocoCategory struct {
+	ID        int      `json:"id"`
+	Name      string   `json:"name"`
+	Keypoints []string `json:"keypoints,omitempty"`
+	Skeleton  [][2]int `json:"skeleton,omitempty"` // 1-based keypoint indices
+}
+
+type cocoDataset struct {
+	Images      []cocoImage      `json:"images"`
+	Annotations []cocoAnnotation `json:"annotations"`
+	Categories  []cocoCategory   `json:"categories"`
+}
+
+// writeCOCO writes annotations.json in the COCO format. Every positive label
+// on a region becomes one annotation with a pixel bounding box. Polygons add
+// a polygon segmentation and masks an uncompressed RLE segmentation. Keypoint
+// regions add COCO keypoints to the annotation of their skeleton's label,
+// whose category lists the keypoint names and skeleton. The label's
+// attributes are written as the annotation's attributes.
+func writeCOCO(ds *Dataset, dir string) error {
+	names := ds.RegionCategories()
+	out := cocoDataset{
+		Images:      []cocoImage{},
+		Annotations: []cocoAnnotation{},
+		Categories:  make([]cocoCategory, 0, len(names)),
+	}
+	for i, name := range names {
+		category := cocoCategory{ID: i + 1, Name: name}
+		if i := slices.IndexFunc(ds.Skeletons, func(s db.Skeleton) bool { return s.Name == name }); i >= 0 {
+			category.Keypoints = ds.Skeletons[i].Keypoints
+			for _, e := range ds.Skeletons[i].Edges {
+				category.Skeleton = append(category.Skeleton, [2]int{e[0] + 1, e[1] + 1})
+			}
+		}
+		out.Categories = append(out.Categories, category)
+	}
+
+	for _, item := range ds.Images() {
+		w, h, ok := ds.imageSize(item)
+		if !ok {
+			continue
+		}
+
+		image := cocoImage{
+			ID:       len(out.Images) + 1,
+			FileName: filepath.ToSlash(item.File.Path),
+			Width:    w,
+			Height:   h,
+		}
+		out.Images = append(out.Images, image)
+
+		for _, region := range item.Regions {
+			bbox := pixelBBox(region.Box, w, h)
+			area := bbox[2] * bbox[3]
+
+			var segmentation any
+			switch region.Kind {
+			case db.RegionPolygon:
+				flat := make([]float64, 0, 2*len(region.Points))
+				for _, p := range region.Points {
+					flat = append(flat, p.X*float64(w), p.Y*float64(h))
+				}
+				segmentation = [][]float64{flat}
+				area = region.Points.Area() * float64(w) * float64(h)
+			case db.RegionMask:
+				if region.Mask.Width != w || region.Mask.Height != h {
+					log.Printf("warning: skipping mask region %d of %s: painted on a %dx%d image, now %dx%d",
+						region.ID, item.File.Path, region.Mask.Width, region.Mask.Height, w, h)
+					continue
+				}
+				segmentation = newCOCORLE(region.Mask)
+				area = float64(region.Mask.Area())
+			}
+
+			for _, label := range positiveLabels(region.Labels) {
+				ann := cocoAnnotation{
+					ID:           len(out.Annotations) + 1,
+					ImageID:      image.ID,
+					CategoryID:   slices.Index(names, label.Name) + 1,
+					BBox:         bbox,
+					Area:         area,
+					Segmentation: segmentation,
+					Attributes:   label.Attributes,
+				}
+				if region.Kind == db.RegionKeypoints && label.ID == region.SkeletonID {
+					ann.Keypoints, ann.NumKeypoints = cocoKeypoints(region.Keypoints, w, h)
+				}
+				out.Annotations = append(out.Annotations, ann)
+			}
+		}
+	}
+
+	f, err := createFile(filepath.Join(dir, "annotations.json"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// cocoKeypoints flattens keypoints to pixel [x, y, v] triples and counts the
+// placed ones. Unplaced keypoints are written as 0, 0, 0.
+func cocoKeypoints(keypoints db.Keypoints, w, h int) ([]float64, int) {
+	flat := make([]float64, 0, 3*len(keypoints))
+	placed := 0
+	for _, k := range keypoints {
+		if k.Visibility == db.KeypointUnlabeled {
+			flat = append(flat, 0, 0, 0)
+			continue
+		}
+		flat = append(flat, k.X*float64(w), k.Y*float64(h), float64(k.Visibility))
+		placed++
+	}
+	return flat, placed
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+type cocoVideo struct {
+	ID       int    `json:"id"`
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type cocoVideoFrame struct {
+	ID       int    `json:"id"`
+	VideoID  int    `json:"video_id"`
+	FrameID  int    `json:"frame_id"` // 0-based
+	FileName string `json:"file_name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+type cocoVideoAnnotation struct {
+	ID         int        `json:"id"`
+	ImageID    int        `json:"image_id"`
+	VideoID    int        `json:"video_id"`
+	InstanceID int64      `json:"instance_id"`
+	CategoryID int        `json:"category_id"`
+	BBox       [4]float64 `json:"bbox"`
+	Area       float64    `json:"area"`
+	IsCrowd    int        `json:"iscrowd"`
+}
+
+type cocoVideoDataset struct {
+	Videos      []cocoVideo           `json:"videos"`
+	Images      []cocoVideoFrame      `json:"images"`
+	Annotations []cocoVideoAnnotation `json:"annotations"`
+	Categories  []cocoCategory        `json:"categories"`
+}
+
+// writeCOCOVideo writes annotations.json in the COCO video layout used by
+// multi-object tracking datasets: videos, their frames as images, and one
+// annotation per track and frame, with the track as instance_id. Only
+// frames within a track's span are listed; their file names point at
+// <path>/<frame>.jpg, as if the video had been split into frames.
+func writeCOCOVideo(ds *Dataset, dir string) error {
+	names := ds.TrackCategories()
+	out := cocoVideoDataset{
+		Videos:      []cocoVideo{},
+		Images:      []cocoVideoFrame{},
+		Annotations: []cocoVideoAnnotation{},
+		Categories:  make([]cocoCategory, 0, len(names)),
+	}
+	for i, name := range names {
+		out.Categories = append(out.Categories, cocoCategory{ID: i + 1, Name: name})
+	}
+
+	for _, item := range ds.Videos() {
+		w, h, ok := ds.videoSize(item)
+		if !ok {
+			continue
+		}
+
+		video := cocoVideo{ID: len(out.Videos) + 1, FileName: filepath.ToSlash(item.File.Path), Width: w, Height: h}
+		out.Videos = append(out.Videos, video)
+
+		frameIDs := map[int]int{}
+		for _, track := range item.Tracks {
+			for _, tf := range ds.trackFrames(track, w, h) {
+				imageID, ok := frameIDs[tf.Frame]
+				if !ok {
+					imageID = len(out.Images) + 1
+					frameIDs[tf.Frame] = imageID
+					out.Images = append(out.Images, cocoVideoFrame{
+						ID:       imageID,
+						VideoID:  video.ID,
+						FrameID:  tf.Frame,
+						FileName: filepath.ToSlash(replaceExt(item.File.Path, fmt.Sprintf("/%06d.jpg", tf.Frame+1))),
+						Width:    w,
+						Height:   h,
+					})
+				}
+
+				out.Annotations = append(out.Annotations, cocoVideoAnnotation{
+					ID:         len(out.Annotations) + 1,
+					ImageID:    imageID,
+					VideoID:    video.ID,
+					InstanceID: track.ID,
+					CategoryID: slices.Index(names, track.Label) + 1,
+					BBox:       tf.BBox,
+					Area:       tf.BBox[2] * tf.BBox[3],
+				})
+			}
+		}
+	}
+
+	slices.SortStableFunc(out.Images, func(a, b cocoVideoFrame) int {
+		if a.VideoID != b.VideoID {
+			return a.VideoID - b.VideoID
+		}
+		return a.FrameID - b.FrameID
+	})
+
+	f, err := createFile(filepath.Join(dir, "annotations.json"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,283 @@
+package export
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/monorkin/just-label-it/internal/imagesize"
+)
+
+// Item is a media file together with all of its annotations.
+type Item struct {
+	File         db.MediaFile
+	Labels       []db.AppliedLabel
+	Descriptions map[string]string // Named descriptions by slot.
+	Fields       db.FieldValues
+	Keyframes    []db.Keyframe
+	Segments     []db.Segment
+	Tracks       []db.Track
+	Spans        []db.Span
+	Regions      []db.Region
+}
+
+// Dataset is the annotated content of a project, ready to be written out.
+type Dataset struct {
+	Root        string  // Directory the media paths are relative to.
+	FrameRate   float64 // Frames per second used to number video frames.
+	Items       []Item
+	Skeletons   []db.Skeleton
+	Comparisons []db.Comparison
+}
+
+// DefaultFrameRate is used when no frame rate is given, since the frame
+// rate of a video isn't stored.
+const DefaultFrameRate = 30
+
+// Format writes a dataset into an output directory.
+type Format struct {
+	Name        string
+	Description string
+	Write       func(ds *Dataset, dir string) error
+}
+
+// Formats lists the supported export formats.
+var Formats = []Format{
+	{Name: "jsonl", Description: "One JSON object per file with all labels, keyframes, segments, spans, regions and tracks", Write: writeManifest},
+	{Name: "coco", Description: "COCO JSON for image regions, with polygon and RLE segmentations and label attributes", Write: writeCOCO},
+	{Name: "yolo", Description: "YOLO txt files for image regions, plus classes.txt; without label attributes", Write: writeYOLO},
+	{Name: "voc", Description: "Pascal VOC XML files for image regions, with label attributes", Write: writeVOC},
+	{Name: "masks", Description: "One PNG mask per image and class for polygon and mask regions; without label attributes", Write: writeMasks},
+	{Name: "mot", Description: "MOT Challenge gt.txt and seqinfo.ini per video for tracks", Write: writeMOT},
+	{Name: "coco-video", Description: "COCO video JSON for tracks, with one image per annotated frame", Write: writeCOCOVideo},
+	{Name: "vtt", Description: "WebVTT subtitles per file from keyframe and segment transcripts", Write: writeVTT},
+	{Name: "srt", Description: "SubRip subtitles per file from keyframe and segment transcripts", Write: writeSRT},
+	{Name: "txt", Description: "Plain-text transcript per file", Write: writeTranscriptText},
+	{Name: "asr", Description: "ASR manifest JSONL with audio_filepath, offset, duration and text per transcript", Write: writeASR},
+	{Name: "ner", Description: "NER JSONL with the text, [start, end, label] entities and their label attributes per text file", Write: writeNER},
+	{Name: "preferences", Description: "Preference-pair JSONL with chosen and rejected files per comparison", Write: writePreferences},
+	{Name: "bradley-terry", Description: "CSV ranking of compared files by Bradley-Terry score", Write: writeBradleyTerry},
+}
+
+// Lookup returns the format with the given name.
+func Lookup(name string) (Format, bool) {
+	i := slices.IndexFunc(Formats, func(f Format) bool { return f.Name == name })
+	if i < 0 {
+		return Format{}, false
+	}
+	return Formats[i], true
+}
+
+// Load reads the media files matching a filter and their annotations from
+// the database.
+func Load(database *db.DB, root string, filter db.FileFilter) (*Dataset, error) {
+	files, err := database.MediaFiles(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ds := &Dataset{Root: root, FrameRate: DefaultFrameRate}
+	if ds.Skeletons, err = database.AllSkeletons(); err != nil {
+		return nil, err
+	}
+	comparisons, err := database.AllComparisons()
+	if err != nil {
+		return nil, err
+	}
+	// Only comparisons between two exported files, so the filter limits
+	// preferences and rankings as it does every other format.
+	exported := make(map[int64]bool, len(files))
+	for _, f := range files {
+		exported[f.ID] = true
+	}
+	for _, c := range comparisons {
+		if exported[c.LeftFileID] && exported[c.RightFileID] {
+			ds.Comparisons = append(ds.Comparisons, c)
+		}
+	}
+
+	for _, f := range files {
+		item := Item{File: f}
+
+		if item.Labels, err = database.AppliedLabels(db.MediaFileLabels, f.ID); err != nil {
+			return nil, err
+		}
+		if item.Keyframes, err = database.KeyframesForMediaFile(f.ID); err != nil {
+			return nil, err
+		}
+		if item.Descriptions, err = database.NamedDescriptions(f.ID); err != nil {
+			return nil, err
+		}
+		if item.Fields, err = database.FieldValuesForMediaFile(f.ID); err != nil {
+			return nil, err
+		}
+		if item.Segments, err = database.SegmentsForMediaFile(f.ID); err != nil {
+			return nil, err
+		}
+		if item.Tracks, err = database.TracksForMediaFile(f.ID); err != nil {
+			return nil, err
+		}
+		if item.Spans, err = database.SpansForMediaFile(f.ID); err != nil {
+			return nil, err
+		}
+		if item.Regions, err = database.RegionsForMediaFile(f.ID); err != nil {
+			return nil, err
+		}
+
+		ds.Items = append(ds.Items, item)
+	}
+
+	return ds, nil
+}
+
+// Images returns the items that are images.
+func (ds *Dataset) Images() []Item {
+	var images []Item
+	for _, item := range ds.Items {
+		if item.File.MediaType == "image" {
+			images = append(images, item)
+		}
+	}
+	return images
+}
+
+// RegionCategories returns the names of all labels applied to regions,
+// sorted by name. Formats use the position in this list as the class ID.
+func (ds *Dataset) RegionCategories() []string {
+	var names []string
+	for _, item := range ds.Items {
+		for _, region := range item.Regions {
+			for _, label := range positiveLabels(region.Labels) {
+				if !slices.Contains(names, label.Name) {
+					names = append(names, label.Name)
+				}
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Videos returns the items that are videos with at least one track.
+func (ds *Dataset) Videos() []Item {
+	var videos []Item
+	for _, item := range ds.Items {
+		if item.File.MediaType == "video" && len(item.Tracks) > 0 {
+			videos = append(videos, item)
+		}
+	}
+	return videos
+}
+
+// TrackCategories returns the names of all labels of tracks, sorted by
+// name. Formats use the position in this list as the class ID.
+func (ds *Dataset) TrackCategories() []string {
+	var names []string
+	for _, item := range ds.Items {
+		for _, track := range item.Tracks {
+			if !slices.Contains(names, track.Label) {
+				names = append(names, track.Label)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// skeleton returns the skeleton template of a label, or nil if it has none.
+func (ds *Dataset) skeleton(labelID int64) *db.Skeleton {
+	i := slices.IndexFunc(ds.Skeletons, func(s db.Skeleton) bool { return s.LabelID == labelID })
+	if i < 0 {
+		return nil
+	}
+	return &ds.Skeletons[i]
+}
+
+// positiveLabels filters out negated labels.
+func positiveLabels(labels []db.AppliedLabel) []db.AppliedLabel {
+	var positive []db.AppliedLabel
+	for _, l := range labels {
+		if !l.Negated {
+			positive = append(positive, l)
+		}
+	}
+	return positive
+}
+
+// createFile creates a file and any missing parent directories.
+func createFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating directory for %s: %w", path, err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// replaceExt swaps the extension of a relative media path, keeping its directories.
+func replaceExt(path, ext string) string {
+	return path[:len(path)-len(filepath.Ext(path))] + ext
+}
+
+// imageSize returns the pixel dimensions of an image item, reading them from
+// the file if they weren't stored yet. Images whose size can't be read are
+// reported and skipped by the formats that need pixels.
+func (ds *Dataset) imageSize(item Item) (int, int, bool) {
+	if item.File.Width > 0 && item.File.Height > 0 {
+		return item.File.Width, item.File.Height, true
+	}
+
+	w, h, err := imagesize.Read(filepath.Join(ds.Root, item.File.Path))
+	if err != nil {
+		log.Printf("warning: skipping regions of %s: %v", item.File.Path, err)
+		return 0, 0, false
+	}
+	return w, h, true
+}
+
+// videoSize returns the pixel dimensions of a video item. Only the browser
+// can read them, so videos that were never opened in the viewer are
+// reported and skipped.
+func (ds *Dataset) videoSize(item Item) (int, int, bool) {
+	if item.File.Width > 0 && item.File.Height > 0 {
+		return item.File.Width, item.File.Height, true
+	}
+	log.Printf("warning: skipping tracks of %s: its size is unknown until it's opened in the viewer", item.File.Path)
+	return 0, 0, false
+}
+
+// trackFrame is the box of a track on one video frame, in pixels.
+type trackFrame struct {
+	Frame int // 0-based
+	BBox  [4]float64
+}
+
+// trackFrames samples a track's interpolated box on every frame of its span.
+func (ds *Dataset) trackFrames(track db.Track, width, height int) []trackFrame {
+	startMs, endMs := track.Span()
+	first := int(math.Ceil(float64(startMs) * ds.FrameRate / 1000))
+	last := int(math.Floor(float64(endMs) * ds.FrameRate / 1000))
+
+	var frames []trackFrame
+	for frame := first; frame <= last; frame++ {
+		ms := int64(math.Round(float64(frame) * 1000 / ds.FrameRate))
+		box, ok := track.BoxAt(min(max(ms, startMs), endMs))
+		if !ok {
+			continue
+		}
+		frames = append(frames, trackFrame{Frame: frame, BBox: pixelBBox(box, width, height)})
+	}
+	return frames
+}
+
+// pixelBBox converts a normalized box to [x, y, width, height] in pixels.
+func pixelBBox(b db.Box, width, height int) [4]float64 {
+	w, h := float64(width), float64(height)
+	return [4]float64{b.X * w, b.Y * h, b.Width * w, b.Height * h}
+}
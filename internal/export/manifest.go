@@ -0,0 +1,207 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// manifestLabel is a label as written to the JSONL manifest.
+type manifestLabel struct {
+	Name       string        `json:"name"`
+	Negated    bool          `json:"negated"`
+	Attributes db.Attributes `json:"attributes,omitempty"`
+}
+
+type manifestKeyframe struct {
+	TimestampMs int64           `json:"timestamp_ms"`
+	Description string          `json:"description"`
+	Transcript  string          `json:"transcript,omitempty"`
+	Language    string          `json:"language,omitempty"`
+	Labels      []manifestLabel `json:"labels"`
+}
+
+type manifestSegment struct {
+	StartMs     int64           `json:"start_ms"`
+	EndMs       int64           `json:"end_ms"`
+	Description string          `json:"description"`
+	Transcript  string          `json:"transcript,omitempty"`
+	Language    string          `json:"language,omitempty"`
+	Labels      []manifestLabel `json:"labels"`
+}
+
+type manifestSpan struct {
+	StartOffset int             `json:"start_offset"`
+	EndOffset   int             `json:"end_offset"`
+	Text        string          `json:"text"`
+	Description string          `json:"description"`
+	Labels      []manifestLabel `json:"labels"`
+}
+
+type manifestTrackBox struct {
+	TimestampMs int64   `json:"timestamp_ms"`
+	X           float64 `json:"x"`
+	Y           float64 `json:"y"`
+	Width       float64 `json:"width"`
+	Height      float64 `json:"height"`
+}
+
+type manifestTrack struct {
+	Label string             `json:"label"`
+	Name  string             `json:"name,omitempty"`
+	Boxes []manifestTrackBox `json:"boxes"`
+}
+
+type manifestRegion struct {
+	Kind        string          `json:"kind"`
+	X           float64         `json:"x"`
+	Y           float64         `json:"y"`
+	Width       float64         `json:"width"`
+	Height      float64         `json:"height"`
+	Points      [][2]float64    `json:"points,omitempty"`
+	Mask        *cocoRLE        `json:"mask,omitempty"`
+	Skeleton    string          `json:"skeleton,omitempty"`
+	Keypoints   [][3]float64    `json:"keypoints,omitempty"`
+	Description string          `json:"description"`
+	Labels      []manifestLabel `json:"labels"`
+}
+
+type manifestEntry struct {
+	Path         string             `json:"path"`
+	MediaType    string             `json:"media_type"`
+	Status       string             `json:"status"`
+	Review       string             `json:"review,omitempty"`
+	Description  string             `json:"description"`
+	Descriptions map[string]string  `json:"descriptions,omitempty"`
+	Labels       []manifestLabel    `json:"labels"`
+	Fields       db.FieldValues     `json:"fields,omitempty"`
+	Keyframes    []manifestKeyframe `json:"keyframes,omitempty"`
+	Segments     []manifestSegment  `json:"segments,omitempty"`
+	Tracks       []manifestTrack    `json:"tracks,omitempty"`
+	Spans        []manifestSpan     `json:"spans,omitempty"`
+	Regions      []manifestRegion   `json:"regions,omitempty"`
+}
+
+// writeManifest writes manifest.jsonl with one line per media file.
+func writeManifest(ds *Dataset, dir string) error {
+	f, err := createFile(filepath.Join(dir, "manifest.jsonl"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, item := range ds.Items {
+		if err := enc.Encode(ds.manifestItem(item)); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (ds *Dataset) manifestItem(item Item) manifestEntry {
+	entry := manifestEntry{
+		Path:         filepath.ToSlash(item.File.Path),
+		MediaType:    item.File.MediaType,
+		Status:       item.File.Status,
+		Review:       item.File.Review,
+		Description:  item.File.Description,
+		Descriptions: item.Descriptions,
+		Labels:       manifestLabels(item.Labels),
+		Fields:       item.Fields,
+	}
+
+	for _, kf := range item.Keyframes {
+		entry.Keyframes = append(entry.Keyframes, manifestKeyframe{
+			TimestampMs: kf.TimestampMs,
+			Description: kf.Description,
+			Transcript:  kf.Transcript,
+			Language:    kf.Language,
+			Labels:      manifestLabels(kf.Labels),
+		})
+	}
+
+	for _, seg := range item.Segments {
+		entry.Segments = append(entry.Segments, manifestSegment{
+			StartMs:     seg.StartMs,
+			EndMs:       seg.EndMs,
+			Description: seg.Description,
+			Transcript:  seg.Transcript,
+			Language:    seg.Language,
+			Labels:      manifestLabels(seg.Labels),
+		})
+	}
+
+	for _, t := range item.Tracks {
+		track := manifestTrack{Label: t.Label, Name: t.Name, Boxes: []manifestTrackBox{}}
+		for _, b := range t.Boxes {
+			track.Boxes = append(track.Boxes, manifestTrackBox{
+				TimestampMs: b.TimestampMs,
+				X:           b.X,
+				Y:           b.Y,
+				Width:       b.Width,
+				Height:      b.Height,
+			})
+		}
+		entry.Tracks = append(entry.Tracks, track)
+	}
+
+	if len(item.Spans) > 0 {
+		if text, ok := ds.documentText(item); ok {
+			for _, span := range ds.validSpans(item, text) {
+				entry.Spans = append(entry.Spans, manifestSpan{
+					StartOffset: span.StartOffset,
+					EndOffset:   span.EndOffset,
+					Text:        string(text[span.StartOffset:span.EndOffset]),
+					Description: span.Description,
+					Labels:      manifestLabels(span.Labels),
+				})
+			}
+		}
+	}
+
+	for _, r := range item.Regions {
+		region := manifestRegion{
+			Kind:        r.Kind,
+			X:           r.X,
+			Y:           r.Y,
+			Width:       r.Width,
+			Height:      r.Height,
+			Description: r.Description,
+			Labels:      manifestLabels(r.Labels),
+		}
+		for _, p := range r.Points {
+			region.Points = append(region.Points, [2]float64{p.X, p.Y})
+		}
+		if !r.Mask.IsZero() {
+			region.Mask = newCOCORLE(r.Mask)
+		}
+		if s := ds.skeleton(r.SkeletonID); s != nil {
+			region.Skeleton = s.Name
+		}
+		for _, k := range r.Keypoints {
+			region.Keypoints = append(region.Keypoints, [3]float64{k.X, k.Y, float64(k.Visibility)})
+		}
+		entry.Regions = append(entry.Regions, region)
+	}
+
+	return entry
+}
+
+func manifestLabels(labels []db.AppliedLabel) []manifestLabel {
+	result := make([]manifestLabel, 0, len(labels))
+	for _, l := range labels {
+		result = append(result, manifestLabel{
+			Name:       l.Name,
+			Negated:    l.Negated,
+			Attributes: l.Attributes,
+		})
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package export
+
+import (
+	"image"
+	"image/png"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// writeMasks writes masks/<path>/<class>.png for every image, one binary
+// PNG per class with white pixels where a polygon or mask region carries
+// that label. Box and keypoint regions are left out.
+func writeMasks(ds *Dataset, dir string) error {
+	for _, item := range ds.Images() {
+		if !slices.ContainsFunc(item.Regions, isSegmentation) {
+			continue
+		}
+
+		w, h, ok := ds.imageSize(item)
+		if !ok {
+			continue
+		}
+
+		masks := map[string]*image.Gray{}
+		for _, region := range item.Regions {
+			if !isSegmentation(region) {
+				continue
+			}
+			if region.Kind == db.RegionMask && (region.Mask.Width != w || region.Mask.Height != h) {
+				log.Printf("warning: skipping mask region %d of %s: painted on a %dx%d image, now %dx%d",
+					region.ID, item.File.Path, region.Mask.Width, region.Mask.Height, w, h)
+				continue
+			}
+
+			for _, label := range positiveLabels(region.Labels) {
+				img, ok := masks[label.Name]
+				if !ok {
+					img = image.NewGray(image.Rect(0, 0, w, h))
+					masks[label.Name] = img
+				}
+
+				switch region.Kind {
+				case db.RegionPolygon:
+					fillPolygon(img, region.Points)
+				case db.RegionMask:
+					fillMask(img, region.Mask)
+				}
+			}
+		}
+
+		base := filepath.Join(dir, "masks", replaceExt(item.File.Path, ""))
+		for name, img := range masks {
+			if err := writePNG(filepath.Join(base, maskFileName(name)), img); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isSegmentation reports whether a region outlines pixels rather than a box or points.
+func isSegmentation(r db.Region) bool {
+	return r.Kind == db.RegionPolygon || r.Kind == db.RegionMask
+}
+
+// fillPolygon paints every pixel whose center lies inside the polygon,
+// using the even-odd rule.
+func fillPolygon(img *image.Gray, points db.Polygon) {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	var xs []float64
+	for y := 0; y < h; y++ {
+		cy := (float64(y) + 0.5) / float64(h)
+
+		xs = xs[:0]
+		for i, p := range points {
+			q := points[(i+1)%len(points)]
+			if (p.Y <= cy) != (q.Y <= cy) {
+				xs = append(xs, p.X+(cy-p.Y)/(q.Y-p.Y)*(q.X-p.X))
+			}
+		}
+		slices.Sort(xs)
+
+		for i := 0; i+1 < len(xs); i += 2 {
+			for x := 0; x < w; x++ {
+				cx := (float64(x) + 0.5) / float64(w)
+				if cx >= xs[i] && cx < xs[i+1] {
+					img.Pix[y*img.Stride+x] = 0xff
+				}
+			}
+		}
+	}
+}
+
+// fillMask paints the foreground runs of a column-major RLE mask.
+func fillMask(img *image.Gray, mask db.Mask) {
+	pos := 0
+	for i, c := range mask.Counts {
+		if i%2 == 1 {
+			for p := pos; p < pos+c; p++ {
+				x, y := p/mask.Height, p%mask.Height
+				img.Pix[y*img.Stride+x] = 0xff
+			}
+		}
+		pos += c
+	}
+}
+
+// maskFileName turns a label name into a safe PNG file name.
+func maskFileName(name string) string {
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(name) + ".png"
+}
+
+func writePNG(path string, img image.Image) error {
+	f, err := createFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return err
+	}
+	return f.Close()
+}
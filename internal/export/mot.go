@@ -0,0 +1,90 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// writeMOT writes a MOT Challenge sequence per video with tracks:
+// <path>/gt/gt.txt with a "frame,id,left,top,width,height,conf,class,visibility"
+// line per track and frame, and <path>/seqinfo.ini describing the sequence.
+// Frames and track IDs are 1-based; classes index classes.txt, also 1-based.
+func writeMOT(ds *Dataset, dir string) error {
+	names := ds.TrackCategories()
+
+	classes, err := createFile(filepath.Join(dir, "classes.txt"))
+	if err != nil {
+		return err
+	}
+	defer classes.Close()
+	if _, err := classes.WriteString(strings.Join(names, "\n") + "\n"); err != nil {
+		return err
+	}
+	if err := classes.Close(); err != nil {
+		return err
+	}
+
+	for _, item := range ds.Videos() {
+		w, h, ok := ds.videoSize(item)
+		if !ok {
+			continue
+		}
+
+		seqDir := filepath.Join(dir, replaceExt(item.File.Path, ""))
+		length, err := writeMOTGroundTruth(ds, filepath.Join(seqDir, "gt", "gt.txt"), item, names, w, h)
+		if err != nil {
+			return err
+		}
+		if item.File.DurationMs > 0 {
+			length = int(math.Floor(float64(item.File.DurationMs) * ds.FrameRate / 1000))
+		}
+		if err := writeSeqInfo(filepath.Join(seqDir, "seqinfo.ini"), ds, item, length, w, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMOTGroundTruth writes gt.txt and returns the number of the last
+// annotated frame.
+func writeMOTGroundTruth(ds *Dataset, path string, item Item, names []string, w, h int) (int, error) {
+	f, err := createFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	last := 0
+	out := bufio.NewWriter(f)
+	for i, track := range item.Tracks {
+		class := slices.Index(names, track.Label) + 1
+		for _, tf := range ds.trackFrames(track, w, h) {
+			fmt.Fprintf(out, "%d,%d,%.2f,%.2f,%.2f,%.2f,1,%d,1\n",
+				tf.Frame+1, i+1, tf.BBox[0], tf.BBox[1], tf.BBox[2], tf.BBox[3], class)
+			last = max(last, tf.Frame+1)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return 0, err
+	}
+	return last, f.Close()
+}
+
+func writeSeqInfo(path string, ds *Dataset, item Item, length, w, h int) error {
+	f, err := createFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "[Sequence]\nname=%s\nimDir=img1\nframeRate=%g\nseqLength=%d\nimWidth=%d\nimHeight=%d\nimExt=.jpg\n",
+		filepath.Base(replaceExt(item.File.Path, "")), ds.FrameRate, length, w, h)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,175 @@
+package export
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// preference is one comparison as written to preferences.jsonl. Chosen and
+// Rejected are left out for ties.
+type preference struct {
+	Left      string    `json:"left"`
+	Right     string    `json:"right"`
+	Winner    string    `json:"winner"`
+	Chosen    string    `json:"chosen,omitempty"`
+	Rejected  string    `json:"rejected,omitempty"`
+	Reason    string    `json:"reason"`
+	Annotator string    `json:"annotator"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// writePreferences writes preferences.jsonl with one line per comparison.
+func writePreferences(ds *Dataset, dir string) error {
+	f, err := createFile(filepath.Join(dir, "preferences.jsonl"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, c := range ds.Comparisons {
+		p := preference{
+			Left:      filepath.ToSlash(c.LeftPath),
+			Right:     filepath.ToSlash(c.RightPath),
+			Winner:    c.Winner,
+			Reason:    c.Reason,
+			Annotator: c.Annotator,
+			CreatedAt: c.CreatedAt,
+		}
+		switch c.Winner {
+		case db.WinnerLeft:
+			p.Chosen, p.Rejected = p.Left, p.Right
+		case db.WinnerRight:
+			p.Chosen, p.Rejected = p.Right, p.Left
+		}
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// ranking is the Bradley-Terry score of one compared file.
+type ranking struct {
+	Path   string
+	Score  float64 // Log strength, relative to the reference every file is anchored to.
+	Wins   int
+	Ties   int
+	Losses int
+}
+
+// writeBradleyTerry writes rankings.csv, ranking every compared file by its
+// Bradley-Terry score, best first.
+func writeBradleyTerry(ds *Dataset, dir string) error {
+	f, err := createFile(filepath.Join(dir, "rankings.csv"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"rank", "path", "score", "wins", "ties", "losses"})
+	for i, r := range bradleyTerry(ds.Comparisons) {
+		w.Write([]string{
+			strconv.Itoa(i + 1),
+			filepath.ToSlash(r.Path),
+			strconv.FormatFloat(r.Score, 'f', 4, 64),
+			strconv.Itoa(r.Wins),
+			strconv.Itoa(r.Ties),
+			strconv.Itoa(r.Losses),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// bradleyTerry fits a Bradley-Terry model to the comparisons with Hunter's
+// MM algorithm, counting a tie as half a win for each side. Every file also
+// gets one virtual tie against a reference of strength 1, which keeps files
+// that never won or never lost at a finite score and anchors the scale.
+func bradleyTerry(comparisons []db.Comparison) []ranking {
+	index := make(map[int64]int)
+	var rankings []ranking
+	fileIndex := func(id int64, path string) int {
+		i, ok := index[id]
+		if !ok {
+			i = len(rankings)
+			index[id] = i
+			rankings = append(rankings, ranking{Path: path})
+		}
+		return i
+	}
+
+	type match struct{ a, b int }
+	var matches []match
+	for _, c := range comparisons {
+		l := fileIndex(c.LeftFileID, c.LeftPath)
+		r := fileIndex(c.RightFileID, c.RightPath)
+		matches = append(matches, match{l, r})
+		switch c.Winner {
+		case db.WinnerLeft:
+			rankings[l].Wins++
+			rankings[r].Losses++
+		case db.WinnerRight:
+			rankings[r].Wins++
+			rankings[l].Losses++
+		default:
+			rankings[l].Ties++
+			rankings[r].Ties++
+		}
+	}
+
+	strength := make([]float64, len(rankings))
+	for i := range strength {
+		strength[i] = 1
+	}
+	for range 1000 {
+		denominators := make([]float64, len(rankings))
+		for i := range denominators {
+			denominators[i] = 1 / (strength[i] + 1)
+		}
+		for _, m := range matches {
+			d := 1 / (strength[m.a] + strength[m.b])
+			denominators[m.a] += d
+			denominators[m.b] += d
+		}
+
+		change := 0.0
+		for i, r := range rankings {
+			wins := float64(r.Wins) + float64(r.Ties)/2 + 0.5
+			next := wins / denominators[i]
+			change = math.Max(change, math.Abs(math.Log(next/strength[i])))
+			strength[i] = next
+		}
+		if change < 1e-9 {
+			break
+		}
+	}
+
+	for i := range rankings {
+		rankings[i].Score = math.Log(strength[i])
+	}
+	slices.SortStableFunc(rankings, func(a, b ranking) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Path, b.Path)
+	})
+	return rankings
+}
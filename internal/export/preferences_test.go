@@ -0,0 +1,113 @@
+package export
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// judgment builds a comparison between files named by their path, using
+// the path's first byte as the file ID.
+func judgment(left, right, winner string) db.Comparison {
+	return db.Comparison{
+		LeftFileID: int64(left[0]), LeftPath: left,
+		RightFileID: int64(right[0]), RightPath: right,
+		Winner: winner,
+	}
+}
+
+func TestBradleyTerry(t *testing.T) {
+	tests := []struct {
+		name        string
+		comparisons []db.Comparison
+		wantOrder   []string
+		wantEqual   bool // Whether every file scores the same.
+	}{
+		{
+			name:        "no comparisons",
+			comparisons: nil,
+			wantOrder:   nil,
+		},
+		{
+			name: "winner first",
+			comparisons: []db.Comparison{
+				judgment("a", "b", db.WinnerRight),
+				judgment("b", "a", db.WinnerLeft),
+			},
+			wantOrder: []string{"b", "a"},
+		},
+		{
+			name: "transitive",
+			comparisons: []db.Comparison{
+				judgment("c", "b", db.WinnerRight),
+				judgment("b", "a", db.WinnerRight),
+				judgment("a", "c", db.WinnerLeft),
+			},
+			wantOrder: []string{"a", "b", "c"},
+		},
+		{
+			name: "ties by path",
+			comparisons: []db.Comparison{
+				judgment("b", "a", db.WinnerTie),
+				judgment("c", "b", db.WinnerTie),
+			},
+			wantOrder: []string{"a", "b", "c"},
+			wantEqual: true,
+		},
+		{
+			name: "split decisions",
+			comparisons: []db.Comparison{
+				judgment("a", "b", db.WinnerLeft),
+				judgment("a", "b", db.WinnerRight),
+			},
+			wantOrder: []string{"a", "b"},
+			wantEqual: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rankings := bradleyTerry(tt.comparisons)
+
+			var order []string
+			for _, r := range rankings {
+				order = append(order, r.Path)
+				if math.IsInf(r.Score, 0) || math.IsNaN(r.Score) {
+					t.Errorf("%s scored %v, want a finite score", r.Path, r.Score)
+				}
+			}
+			if !slices.Equal(order, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+
+			for _, r := range rankings[min(1, len(rankings)):] {
+				equal := math.Abs(r.Score-rankings[0].Score) < 1e-6
+				if equal != tt.wantEqual {
+					t.Errorf("%s scored %v and %s %v, want equal: %v", rankings[0].Path, rankings[0].Score, r.Path, r.Score, tt.wantEqual)
+				}
+			}
+		})
+	}
+}
+
+func TestBradleyTerryCounts(t *testing.T) {
+	rankings := bradleyTerry([]db.Comparison{
+		judgment("a", "b", db.WinnerLeft),
+		judgment("a", "c", db.WinnerLeft),
+		judgment("b", "a", db.WinnerTie),
+		judgment("c", "b", db.WinnerLeft),
+	})
+
+	want := map[string][3]int{ // Wins, ties, losses.
+		"a": {2, 1, 0},
+		"b": {0, 1, 2},
+		"c": {1, 0, 1},
+	}
+	for _, r := range rankings {
+		if got := [3]int{r.Wins, r.Ties, r.Losses}; got != want[r.Path] {
+			t.Errorf("%s has wins, ties, losses %v, want %v", r.Path, got, want[r.Path])
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// nerDocument is one text file as written to ner.jsonl. Entities are
+// [start, end, label] triples of code point offsets, as spaCy and most NER
+// tooling read them. EntityAttributes holds the label attributes of each
+// entity, in the same order, when any entity has some.
+type nerDocument struct {
+	Path             string          `json:"path"`
+	Text             string          `json:"text"`
+	Entities         [][]any         `json:"entities"`
+	EntityAttributes []db.Attributes `json:"entity_attributes,omitempty"`
+}
+
+// writeNER writes ner.jsonl with one line per text file that has spans.
+// A span with several labels becomes one entity per label.
+func writeNER(ds *Dataset, dir string) error {
+	f, err := createFile(filepath.Join(dir, "ner.jsonl"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, item := range ds.Items {
+		if len(item.Spans) == 0 {
+			continue
+		}
+		text, ok := ds.documentText(item)
+		if !ok {
+			continue
+		}
+
+		doc := nerDocument{Path: filepath.ToSlash(item.File.Path), Text: string(text), Entities: [][]any{}}
+		var attributes []db.Attributes
+		withAttributes := false
+		for _, span := range ds.validSpans(item, text) {
+			for _, label := range positiveLabels(span.Labels) {
+				doc.Entities = append(doc.Entities, []any{span.StartOffset, span.EndOffset, label.Name})
+				attributes = append(attributes, label.Attributes)
+				withAttributes = withAttributes || len(label.Attributes) > 0
+			}
+		}
+		if withAttributes {
+			doc.EntityAttributes = attributes
+		}
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// documentText reads a text item as code points, so span offsets index it
+// directly. Files that can't be read are reported and skipped.
+func (ds *Dataset) documentText(item Item) ([]rune, bool) {
+	content, err := os.ReadFile(filepath.Join(ds.Root, item.File.Path))
+	if err != nil {
+		log.Printf("warning: skipping spans of %s: %v", item.File.Path, err)
+		return nil, false
+	}
+	return []rune(string(content)), true
+}
+
+// validSpans returns the spans of an item that lie within its text. Spans
+// past the end, left behind when the file was shortened, are reported and
+// skipped.
+func (ds *Dataset) validSpans(item Item, text []rune) []db.Span {
+	var spans []db.Span
+	for _, span := range item.Spans {
+		if span.EndOffset > len(text) {
+			log.Printf("warning: skipping span %d-%d of %s: the file is only %d characters long",
+				span.StartOffset, span.EndOffset, item.File.Path, len(text))
+			continue
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
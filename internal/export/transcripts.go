@@ -0,0 +1,197 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// lastCueMs is how long the transcript of the last keyframe is taken to
+// last when the duration of the file is unknown.
+const lastCueMs = 5000
+
+// cue is a piece of transcript with the time range it's spoken in.
+type cue struct {
+	StartMs  int64
+	EndMs    int64
+	Text     string
+	Language string
+}
+
+// transcriptCues returns the transcripts of an item's segments and
+// keyframes, ordered by start time. A keyframe's transcript runs until the
+// next keyframe, or the end of the file.
+func (ds *Dataset) transcriptCues(item Item) []cue {
+	var cues []cue
+	for _, seg := range item.Segments {
+		if text := cueText(seg.Transcript); text != "" {
+			cues = append(cues, cue{StartMs: seg.StartMs, EndMs: seg.EndMs, Text: text, Language: seg.Language})
+		}
+	}
+
+	for i, kf := range item.Keyframes {
+		text := cueText(kf.Transcript)
+		if text == "" {
+			continue
+		}
+
+		end := kf.TimestampMs + lastCueMs
+		if i+1 < len(item.Keyframes) {
+			end = item.Keyframes[i+1].TimestampMs
+		} else if item.File.DurationMs > kf.TimestampMs {
+			end = item.File.DurationMs
+		}
+		if end > kf.TimestampMs {
+			cues = append(cues, cue{StartMs: kf.TimestampMs, EndMs: end, Text: text, Language: kf.Language})
+		}
+	}
+
+	slices.SortStableFunc(cues, func(a, b cue) int { return int(a.StartMs - b.StartMs) })
+	return cues
+}
+
+// cueText trims every line of a transcript and drops blank lines, which
+// would end a subtitle cue early.
+func cueText(transcript string) string {
+	var lines []string
+	for _, line := range strings.Split(transcript, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeVTT writes a WebVTT file next to the path of every file with a
+// transcript. A file whose cues share one language names it in the header;
+// otherwise each cue with a language is wrapped in a <lang> span.
+func writeVTT(ds *Dataset, dir string) error {
+	return writeTranscripts(ds, dir, ".vtt", func(w *bufio.Writer, cues []cue) {
+		languages := cueLanguages(cues)
+		w.WriteString("WEBVTT\n")
+		if len(languages) == 1 && languages[0] != "" {
+			fmt.Fprintf(w, "Language: %s\n", languages[0])
+		}
+
+		escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+		for _, c := range cues {
+			text := escape.Replace(c.Text)
+			if len(languages) > 1 && c.Language != "" {
+				text = fmt.Sprintf("<lang %s>%s</lang>", c.Language, text)
+			}
+			fmt.Fprintf(w, "\n%s --> %s\n%s\n", cueTimestamp(c.StartMs, "."), cueTimestamp(c.EndMs, "."), text)
+		}
+	})
+}
+
+// writeSRT writes a SubRip file next to the path of every file with a transcript.
+func writeSRT(ds *Dataset, dir string) error {
+	return writeTranscripts(ds, dir, ".srt", func(w *bufio.Writer, cues []cue) {
+		for i, c := range cues {
+			if i > 0 {
+				w.WriteString("\n")
+			}
+			fmt.Fprintf(w, "%d\n%s --> %s\n%s\n", i+1, cueTimestamp(c.StartMs, ","), cueTimestamp(c.EndMs, ","), c.Text)
+		}
+	})
+}
+
+// writeTranscriptText writes the plain transcript of every file, one cue per line.
+func writeTranscriptText(ds *Dataset, dir string) error {
+	return writeTranscripts(ds, dir, ".txt", func(w *bufio.Writer, cues []cue) {
+		for _, c := range cues {
+			w.WriteString(c.Text + "\n")
+		}
+	})
+}
+
+func writeTranscripts(ds *Dataset, dir, ext string, write func(*bufio.Writer, []cue)) error {
+	for _, item := range ds.Items {
+		cues := ds.transcriptCues(item)
+		if len(cues) == 0 {
+			continue
+		}
+
+		f, err := createFile(filepath.Join(dir, replaceExt(item.File.Path, ext)))
+		if err != nil {
+			return err
+		}
+		w := bufio.NewWriter(f)
+		write(w, cues)
+		if err := w.Flush(); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cueLanguages returns the distinct languages of the cues, in order of appearance.
+func cueLanguages(cues []cue) []string {
+	var languages []string
+	for _, c := range cues {
+		if !slices.Contains(languages, c.Language) {
+			languages = append(languages, c.Language)
+		}
+	}
+	return languages
+}
+
+// cueTimestamp formats milliseconds as HH:MM:SS.mmm, with the given
+// separator before the milliseconds: "." for WebVTT, "," for SRT.
+func cueTimestamp(ms int64, sep string) string {
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// asrEntry is a line of an ASR training manifest, as read by NeMo and
+// similar toolkits. Times are in seconds.
+type asrEntry struct {
+	AudioFilepath string  `json:"audio_filepath"`
+	Offset        float64 `json:"offset"`
+	Duration      float64 `json:"duration"`
+	Text          string  `json:"text"`
+	Language      string  `json:"lang,omitempty"`
+}
+
+// writeASR writes asr_manifest.jsonl with one line per transcript cue,
+// pointing at the absolute path of its file.
+func writeASR(ds *Dataset, dir string) error {
+	root, err := filepath.Abs(ds.Root)
+	if err != nil {
+		return err
+	}
+
+	f, err := createFile(filepath.Join(dir, "asr_manifest.jsonl"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, item := range ds.Items {
+		for _, c := range ds.transcriptCues(item) {
+			entry := asrEntry{
+				AudioFilepath: filepath.Join(root, item.File.Path),
+				Offset:        float64(c.StartMs) / 1000,
+				Duration:      float64(c.EndMs-c.StartMs) / 1000,
+				Text:          strings.ReplaceAll(c.Text, "\n", " "),
+				Language:      c.Language,
+			}
+			if err := enc.Encode(entry); err != nil {
+				return err
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
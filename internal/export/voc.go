@@ -0,0 +1,111 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+type vocAnnotation struct {
+	XMLName  xml.Name    `xml:"annotation"`
+	Filename string      `xml:"filename"`
+	Path     string      `xml:"path"`
+	Size     vocSize     `xml:"size"`
+	Objects  []vocObject `xml:"object"`
+}
+
+type vocSize struct {
+	Width  int `xml:"width"`
+	Height int `xml:"height"`
+	Depth  int `xml:"depth"`
+}
+
+type vocObject struct {
+	Name       string         `xml:"name"`
+	Truncated  int            `xml:"truncated"`
+	Difficult  int            `xml:"difficult"`
+	BndBox     vocBox         `xml:"bndbox"`
+	Attributes []vocAttribute `xml:"attributes>attribute,omitempty"`
+}
+
+// vocAttribute is an attribute of an object's label, as CVAT writes them.
+type vocAttribute struct {
+	Name  string `xml:"name"`
+	Value string `xml:"value"`
+}
+
+// vocAttributes returns label attributes sorted by name.
+func vocAttributes(attrs db.Attributes) []vocAttribute {
+	var out []vocAttribute
+	for name, value := range attrs {
+		out = append(out, vocAttribute{Name: name, Value: fmt.Sprint(value)})
+	}
+	slices.SortFunc(out, func(a, b vocAttribute) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return out
+}
+
+type vocBox struct {
+	XMin int `xml:"xmin"`
+	YMin int `xml:"ymin"`
+	XMax int `xml:"xmax"`
+	YMax int `xml:"ymax"`
+}
+
+// writeVOC writes one Annotations/<path>.xml file per image in the Pascal
+// VOC format. Box corners are 1-based pixel coordinates. Label attributes
+// are written as the object's attributes.
+func writeVOC(ds *Dataset, dir string) error {
+	for _, item := range ds.Images() {
+		w, h, ok := ds.imageSize(item)
+		if !ok {
+			continue
+		}
+
+		ann := vocAnnotation{
+			Filename: filepath.Base(item.File.Path),
+			Path:     filepath.ToSlash(item.File.Path),
+			Size:     vocSize{Width: w, Height: h, Depth: 3},
+		}
+		for _, region := range item.Regions {
+			box := vocBox{
+				XMin: int(math.Round(region.X*float64(w))) + 1,
+				YMin: int(math.Round(region.Y*float64(h))) + 1,
+				XMax: int(math.Round((region.X + region.Width) * float64(w))),
+				YMax: int(math.Round((region.Y + region.Height) * float64(h))),
+			}
+			for _, label := range positiveLabels(region.Labels) {
+				ann.Objects = append(ann.Objects, vocObject{Name: label.Name, BndBox: box, Attributes: vocAttributes(label.Attributes)})
+			}
+		}
+
+		if err := writeVOCFile(filepath.Join(dir, "Annotations", replaceExt(item.File.Path, ".xml")), ann); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeVOCFile(path string, ann vocAnnotation) error {
+	f, err := createFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "  ")
+	if err := enc.Encode(ann); err != nil {
+		return err
+	}
+	if _, err := f.WriteString("\n"); err != nil {
+		return err
+	}
+	return f.Close()
+}
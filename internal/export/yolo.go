@@ -0,0 +1,58 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// writeYOLO writes one labels/<path>.txt file per image, with a
+// "class cx cy w h" line per positive region label, and classes.txt listing
+// the class names by index. Coordinates are already normalized, so no image
+// sizes are needed.
+func writeYOLO(ds *Dataset, dir string) error {
+	names := ds.RegionCategories()
+
+	classes, err := createFile(filepath.Join(dir, "classes.txt"))
+	if err != nil {
+		return err
+	}
+	defer classes.Close()
+	if _, err := classes.WriteString(strings.Join(names, "\n") + "\n"); err != nil {
+		return err
+	}
+	if err := classes.Close(); err != nil {
+		return err
+	}
+
+	for _, item := range ds.Images() {
+		if err := writeYOLOLabels(filepath.Join(dir, "labels", replaceExt(item.File.Path, ".txt")), item, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeYOLOLabels(path string, item Item, names []string) error {
+	f, err := createFile(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, region := range item.Regions {
+		cx := region.X + region.Width/2
+		cy := region.Y + region.Height/2
+		for _, label := range positiveLabels(region.Labels) {
+			fmt.Fprintf(w, "%d %.6f %.6f %.6f %.6f\n",
+				slices.Index(names, label.Name), cx, cy, region.Width, region.Height)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
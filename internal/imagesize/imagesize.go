@@ -0,0 +1,81 @@
+package imagesize
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+)
+
+// ErrUnsupported is returned for image formats whose dimensions can't be read.
+var ErrUnsupported = errors.New("unsupported image format")
+
+// Read returns the pixel width and height of an image file without decoding it.
+// JPEG, PNG and GIF are read with the standard library; BMP and WebP headers
+// are parsed directly.
+func Read(path string) (int, int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	header, err := r.Peek(30)
+	if err != nil && err != io.EOF {
+		return 0, 0, fmt.Errorf("reading header of %s: %w", path, err)
+	}
+
+	switch {
+	case len(header) >= 26 && string(header[:2]) == "BM":
+		return readBMP(header)
+	case len(header) >= 30 && string(header[:4]) == "RIFF" && string(header[8:12]) == "WEBP":
+		return readWebP(header)
+	}
+
+	cfg, _, err := image.DecodeConfig(r)
+	if errors.Is(err, image.ErrFormat) {
+		return 0, 0, fmt.Errorf("%s: %w", path, ErrUnsupported)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading dimensions of %s: %w", path, err)
+	}
+	return cfg.Width, cfg.Height, nil
+}
+
+// readBMP reads the dimensions from a BITMAPINFOHEADER. The height is
+// negative for top-down bitmaps.
+func readBMP(header []byte) (int, int, error) {
+	width := int32(binary.LittleEndian.Uint32(header[18:22]))
+	height := int32(binary.LittleEndian.Uint32(header[22:26]))
+	if height < 0 {
+		height = -height
+	}
+	return int(width), int(height), nil
+}
+
+// readWebP reads the dimensions from the first chunk of a WebP file, which
+// is VP8 (lossy), VP8L (lossless) or VP8X (extended).
+func readWebP(header []byte) (int, int, error) {
+	chunk := header[12:]
+	switch string(chunk[:4]) {
+	case "VP8 ":
+		width := int(binary.LittleEndian.Uint16(chunk[14:16]) & 0x3fff)
+		height := int(binary.LittleEndian.Uint16(chunk[16:18]) & 0x3fff)
+		return width, height, nil
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(chunk[9:13])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, nil
+	case "VP8X":
+		width := int(uint32(chunk[12]) | uint32(chunk[13])<<8 | uint32(chunk[14])<<16)
+		height := int(uint32(chunk[15]) | uint32(chunk[16])<<8 | uint32(chunk[17])<<16)
+		return width + 1, height + 1, nil
+	}
+	return 0, 0, ErrUnsupported
+}
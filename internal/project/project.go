@@ -0,0 +1,122 @@
+// Package project reads the optional per-project configuration file, which
+// declares the form fields and description slots every media file of the
+// project can fill in, and the users who review the annotators' work.
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// FileName is the name of the configuration file, kept next to jli.db in
+// the project directory.
+const FileName = "jli.json"
+
+// Config is the configuration of a project. A project without a
+// configuration file gets the zero Config.
+type Config struct {
+	Fields       []db.FieldDefinition `json:"fields"`
+	Descriptions []DescriptionSlot    `json:"descriptions"`
+	Reviewers    []string             `json:"reviewers"` // Users, as given with --user, who approve and reject files.
+}
+
+// DescriptionSlot is a named free-text description kept next to the main
+// description of every file, e.g. a one-line caption or alt text.
+type DescriptionSlot struct {
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"` // Heading in the viewer; defaults to Name.
+	Placeholder string `json:"placeholder,omitempty"`
+}
+
+// slotNamePattern keeps slot names usable in URLs and as export keys.
+var slotNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Heading returns the title of the slot, or its name if it has none.
+func (s DescriptionSlot) Heading() string {
+	if s.Title != "" {
+		return s.Title
+	}
+	return s.Name
+}
+
+// Load reads and validates the configuration of the project in dir.
+func Load(dir string) (*Config, error) {
+	path := filepath.Join(dir, FileName)
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func (c *Config) validate() error {
+	var names []string
+	for _, f := range c.Fields {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+		if slices.Contains(names, f.Name) {
+			return fmt.Errorf("field %q is declared twice", f.Name)
+		}
+		names = append(names, f.Name)
+	}
+
+	var slots []string
+	for _, d := range c.Descriptions {
+		if !slotNamePattern.MatchString(d.Name) {
+			return fmt.Errorf("description slot %q must be named with letters, digits, - and _ only", d.Name)
+		}
+		if slices.Contains(slots, d.Name) {
+			return fmt.Errorf("description slot %q is declared twice", d.Name)
+		}
+		slots = append(slots, d.Name)
+	}
+
+	for _, r := range c.Reviewers {
+		if r == "" {
+			return fmt.Errorf("reviewer names can't be empty")
+		}
+	}
+	return nil
+}
+
+// IsReviewer reports whether the user may approve and reject files.
+func (c *Config) IsReviewer(user string) bool {
+	return user != "" && slices.Contains(c.Reviewers, user)
+}
+
+// Field returns the definition of the named form field.
+func (c *Config) Field(name string) (db.FieldDefinition, bool) {
+	i := slices.IndexFunc(c.Fields, func(f db.FieldDefinition) bool { return f.Name == name })
+	if i < 0 {
+		return db.FieldDefinition{}, false
+	}
+	return c.Fields[i], true
+}
+
+// DescriptionSlot returns the named description slot.
+func (c *Config) DescriptionSlot(name string) (DescriptionSlot, bool) {
+	i := slices.IndexFunc(c.Descriptions, func(d DescriptionSlot) bool { return d.Name == name })
+	if i < 0 {
+		return DescriptionSlot{}, false
+	}
+	return c.Descriptions[i], true
+}
@@ -4,6 +4,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/monorkin/just-label-it/internal/project"
 )
 
 // mediaExtensions maps file extensions to their media type.
@@ -38,12 +41,19 @@ var mediaExtensions = map[string]string{
 	".m4a":  "audio",
 	".wma":  "audio",
 	".opus": "audio",
+
+	// Text
+	".txt":  "text",
+	".md":   "text",
+	".json": "text",
 }
 
 // File represents a discovered media file.
 type File struct {
 	Path      string // Relative path from the scan root.
-	MediaType string // "image", "video", or "audio".
+	MediaType string // "image", "video", "audio", or "text".
+	Size      int64  // In bytes.
+	ModTime   time.Time
 }
 
 // Scan walks a directory tree and returns all recognized media files,
@@ -70,7 +80,17 @@ func Scan(root string) ([]File, error) {
 			return err
 		}
 
-		files = append(files, File{Path: rel, MediaType: mediaType})
+		// The project configuration isn't a document to label.
+		if rel == project.FileName {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, File{Path: rel, MediaType: mediaType, Size: info.Size(), ModTime: info.ModTime()})
 		return nil
 	})
 
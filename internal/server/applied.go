@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// handleAddAppliedLabel returns a handler that adds a label, by name, to
+// an annotation of the target's kind, creating the label if needed.
+func (s *Server) handleAddAppliedLabel(target db.LabelTarget) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r, "id")
+		if err != nil {
+			http.Error(w, "Invalid "+target.Name+" ID", http.StatusBadRequest)
+			return
+		}
+
+		var body struct {
+			Name    string `json:"name"`
+			Negated bool   `json:"negated"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		label, err := s.db.FindOrCreateLabel(strings.TrimSpace(body.Name))
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error creating label %q: %v", body.Name, err)
+			return
+		}
+
+		if err := s.db.AddAppliedLabel(target, id, label.ID, body.Negated); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error adding label to %s %d: %v", target.Name, id, err)
+			return
+		}
+
+		s.recent.touch(label.ID)
+
+		applied, err := s.db.GetAppliedLabel(target, id, label.ID)
+		if err != nil || applied == nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching label %d of %s %d: %v", label.ID, target.Name, id, err)
+			return
+		}
+
+		respondJSON(w, http.StatusCreated, applied)
+	}
+}
+
+// handleUpdateAppliedLabel returns a handler that changes the attributes
+// or polarity of a label on an annotation of the target's kind.
+func (s *Server) handleUpdateAppliedLabel(target db.LabelTarget) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r, "id")
+		if err != nil {
+			http.Error(w, "Invalid "+target.Name+" ID", http.StatusBadRequest)
+			return
+		}
+
+		labelID, err := parseID(r, "lid")
+		if err != nil {
+			http.Error(w, "Invalid label ID", http.StatusBadRequest)
+			return
+		}
+
+		var body struct {
+			Attributes map[string]any `json:"attributes"`
+			Negated    *bool          `json:"negated"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if body.Attributes != nil {
+			if _, err := s.db.SetAppliedLabelAttributes(target, id, labelID, body.Attributes); err != nil {
+				switch {
+				case errors.Is(err, db.ErrLabelNotAssigned):
+					http.Error(w, "Label not found", http.StatusNotFound)
+				case errors.Is(err, db.ErrInvalidAttributes):
+					http.Error(w, err.Error(), http.StatusBadRequest)
+				default:
+					http.Error(w, "Internal server error", http.StatusInternalServerError)
+					log.Printf("error updating label %d on %s %d: %v", labelID, target.Name, id, err)
+				}
+				return
+			}
+		}
+
+		if body.Negated != nil {
+			if err := s.db.SetAppliedLabelNegated(target, id, labelID, *body.Negated); err != nil {
+				if errors.Is(err, db.ErrLabelNotAssigned) {
+					http.Error(w, "Label not found", http.StatusNotFound)
+					return
+				}
+				http.Error(w, "Internal server error", http.StatusInternalServerError)
+				log.Printf("error updating label %d on %s %d: %v", labelID, target.Name, id, err)
+				return
+			}
+		}
+
+		applied, err := s.db.GetAppliedLabel(target, id, labelID)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching label %d of %s %d: %v", labelID, target.Name, id, err)
+			return
+		}
+		if applied == nil {
+			http.Error(w, "Label not found", http.StatusNotFound)
+			return
+		}
+
+		respondJSON(w, http.StatusOK, applied)
+	}
+}
+
+// handleRemoveAppliedLabel returns a handler that removes a label from an
+// annotation of the target's kind.
+func (s *Server) handleRemoveAppliedLabel(target db.LabelTarget) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseID(r, "id")
+		if err != nil {
+			http.Error(w, "Invalid "+target.Name+" ID", http.StatusBadRequest)
+			return
+		}
+
+		labelID, err := parseID(r, "lid")
+		if err != nil {
+			http.Error(w, "Invalid label ID", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.db.RemoveAppliedLabel(target, id, labelID); err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error removing label %d from %s %d: %v", labelID, target.Name, id, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// bulkBody selects files and, when applying, says what to change on them.
+type bulkBody struct {
+	IDs               []int64 `json:"ids"`
+	Filter            string  `json:"filter"` // Filter as a viewer query string, e.g. "label=cat&path=2024/".
+	AddLabel          string  `json:"add_label"`
+	RemoveLabel       string  `json:"remove_label"`
+	Status            string  `json:"status"`
+	AppendDescription string  `json:"append_description"`
+}
+
+// selection returns the files a bulk request's body selects.
+func (s *Server) selection(b bulkBody) (db.Selection, error) {
+	q, err := url.ParseQuery(strings.TrimPrefix(b.Filter, "?"))
+	if err != nil {
+		return db.Selection{}, errors.New("invalid filter")
+	}
+	filter, err := s.filterFromValues(q)
+	if err != nil {
+		return db.Selection{}, err
+	}
+	return db.Selection{IDs: b.IDs, Filter: filter}, nil
+}
+
+// isBulkError reports whether a bulk edit failed because of its request.
+func isBulkError(err error) bool {
+	return errors.Is(err, db.ErrInvalidBulkEdit) ||
+		errors.Is(err, db.ErrInvalidFilter) ||
+		errors.Is(err, db.ErrInvalidQuery) ||
+		errors.Is(err, db.ErrInvalidStatus)
+}
+
+// handleBulkPreview returns how many files a bulk edit would change.
+func (s *Server) handleBulkPreview(w http.ResponseWriter, r *http.Request) {
+	var body bulkBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sel, err := s.selection(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count, err := s.db.CountSelection(sel)
+	if err != nil {
+		if isBulkError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error counting bulk selection: %v", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]int{"count": count})
+}
+
+// handleBulkEdit applies one set of changes to every selected file.
+func (s *Server) handleBulkEdit(w http.ResponseWriter, r *http.Request) {
+	var body bulkBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	sel, err := s.selection(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	count, err := s.db.ApplyBulkEdit(sel, db.BulkEdit{
+		AddLabel:          body.AddLabel,
+		RemoveLabel:       body.RemoveLabel,
+		Status:            body.Status,
+		AppendDescription: body.AppendDescription,
+	})
+	if err != nil {
+		if isBulkError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error applying bulk edit: %v", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]int{"count": count})
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// commentsData is the template data for the comments inbox.
+type commentsData struct {
+	Threads []db.CommentThread
+}
+
+// handleComments renders the inbox of open comment threads.
+func (s *Server) handleComments(w http.ResponseWriter, r *http.Request) {
+	threads, err := s.db.OpenCommentThreads()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching open comment threads: %v", err)
+		return
+	}
+
+	s.renderTemplate(w, "comments.html", commentsData{Threads: threads})
+}
+
+// commentBody is the request body for starting a thread or replying to one.
+type commentBody struct {
+	KeyframeID int64  `json:"keyframe_id"` // Only when starting a thread; 0 for the whole file.
+	Body       string `json:"body"`
+}
+
+// handleCreateCommentThread starts a comment thread on a file or one of its
+// keyframes.
+func (s *Server) handleCreateCommentThread(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	var body commentBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	file, err := s.db.GetMediaFile(fileID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching media file %d: %v", fileID, err)
+		return
+	}
+	if file == nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	thread, err := s.db.CreateCommentThread(fileID, body.KeyframeID, s.user, body.Body)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidComment) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error creating comment thread for media file %d: %v", fileID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, thread)
+}
+
+// handleAddComment replies to a comment thread.
+func (s *Server) handleAddComment(w http.ResponseWriter, r *http.Request) {
+	threadID, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid thread ID", http.StatusBadRequest)
+		return
+	}
+
+	var body commentBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	thread, err := s.db.GetCommentThread(threadID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching comment thread %d: %v", threadID, err)
+		return
+	}
+	if thread == nil {
+		http.Error(w, "Thread not found", http.StatusNotFound)
+		return
+	}
+
+	comment, err := s.db.AddComment(threadID, s.user, body.Body)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidComment) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error adding comment to thread %d: %v", threadID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, comment)
+}
+
+// handleUpdateCommentThreadResolved resolves or reopens a comment thread.
+func (s *Server) handleUpdateCommentThreadResolved(w http.ResponseWriter, r *http.Request) {
+	threadID, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid thread ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Resolved bool `json:"resolved"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	thread, err := s.db.GetCommentThread(threadID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching comment thread %d: %v", threadID, err)
+		return
+	}
+	if thread == nil {
+		http.Error(w, "Thread not found", http.StatusNotFound)
+		return
+	}
+
+	if err := s.db.SetCommentThreadResolved(threadID, body.Resolved, s.user); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating comment thread %d: %v", threadID, err)
+		return
+	}
+
+	thread, err = s.db.GetCommentThread(threadID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching comment thread %d: %v", threadID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, thread)
+}
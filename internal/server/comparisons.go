@@ -0,0 +1,93 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// compareData is the template data for the comparison page.
+type compareData struct {
+	Left   *db.MediaFile
+	Right  *db.MediaFile
+	Filter db.PairFilter
+	User   string
+	Count  int // Judgments made by User so far.
+}
+
+// comparisonBody is the request body for recording a judgment.
+type comparisonBody struct {
+	LeftID  int64  `json:"left_id"`
+	RightID int64  `json:"right_id"`
+	Winner  string `json:"winner"`
+	Reason  string `json:"reason"`
+}
+
+// handleCompare shows two files side by side to judge which one is better,
+// optionally sampled only from files with a label or within a directory.
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	filter := db.PairFilter{
+		Label:     r.URL.Query().Get("label"),
+		Directory: r.URL.Query().Get("dir"),
+	}
+
+	left, right, err := s.db.SamplePair(filter, s.user)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error sampling pair: %v", err)
+		return
+	}
+
+	count, err := s.db.ComparisonCount(s.user)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error counting comparisons: %v", err)
+		return
+	}
+
+	s.renderTemplate(w, "compare.html", compareData{
+		Left:   left,
+		Right:  right,
+		Filter: filter,
+		User:   s.user,
+		Count:  count,
+	})
+}
+
+// handleCreateComparison records which of two files the annotator prefers.
+func (s *Server) handleCreateComparison(w http.ResponseWriter, r *http.Request) {
+	var body comparisonBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	for _, id := range []int64{body.LeftID, body.RightID} {
+		file, err := s.db.GetMediaFile(id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching media file %d: %v", id, err)
+			return
+		}
+		if file == nil {
+			http.Error(w, "File not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	comparison, err := s.db.CreateComparison(body.LeftID, body.RightID, body.Winner, body.Reason, s.user)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidComparison) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error creating comparison: %v", err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, comparison)
+}
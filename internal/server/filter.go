@@ -0,0 +1,72 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// parseFileFilter reads the file filter carried in a request's query string.
+func (s *Server) parseFileFilter(r *http.Request) (db.FileFilter, error) {
+	return s.filterFromValues(r.URL.Query())
+}
+
+// filterFromValues reads a file filter from query parameters, the inverse
+// of filterQuery.
+func (s *Server) filterFromValues(q url.Values) (db.FileFilter, error) {
+	filter := db.FileFilter{
+		Label:       q.Get("label"),
+		NoLabel:     q.Get("nolabel"),
+		MediaType:   q.Get("type"),
+		Path:        q.Get("path"),
+		Status:      q.Get("status"),
+		Review:      q.Get("review"),
+		Description: q.Get("desc"),
+		Queue:       q.Get("queue"),
+		Query:       q.Get("q"),
+		Fields:      s.project.Fields,
+	}
+	return filter, filter.Validate()
+}
+
+// filterQuery encodes a file filter as a query string to append to viewer
+// links, so navigation stays within the filtered files. It returns "" for
+// the zero filter.
+func filterQuery(filter db.FileFilter) string {
+	if filter.IsZero() {
+		return ""
+	}
+
+	q := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	set("label", filter.Label)
+	set("nolabel", filter.NoLabel)
+	set("type", filter.MediaType)
+	set("path", filter.Path)
+	set("status", filter.Status)
+	set("review", filter.Review)
+	set("desc", filter.Description)
+	set("queue", filter.Queue)
+	set("q", filter.Query)
+	return "?" + q.Encode()
+}
+
+// queueNames are the queue titles shown in the viewer.
+var queueNames = map[string]string{
+	db.QueueUnlabeled:   "Unlabeled",
+	db.QueueUndescribed: "Undescribed",
+	db.QueueNotDone:     "Not done",
+	db.QueueNoKeyframes: "Missing keyframes",
+}
+
+// reviewNames are the review states shown in the filter.
+var reviewNames = map[string]string{
+	db.ReviewPending:  "Awaiting review",
+	db.ReviewApproved: "Approved",
+	db.ReviewRejected: "Rejected",
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// galleryPageSize is how many thumbnails a gallery page shows.
+const galleryPageSize = 60
+
+// galleryItem is one thumbnail in the gallery.
+type galleryItem struct {
+	File   db.MediaFile
+	Labels []db.AppliedLabel
+}
+
+// galleryData is the template data for the gallery page.
+type galleryData struct {
+	Items     []galleryItem
+	Total     int
+	Page      int
+	Pages     int
+	PrevURL   string // Empty on the first page.
+	NextURL   string // Empty on the last page.
+	Filter    db.FileFilter
+	Query     string // Filter query appended to viewer links.
+	Statuses  []string
+	Reviews   []string
+	Queues    []string
+	Types     []string
+	AllLabels []db.Label
+}
+
+// handleGallery renders a page of thumbnails of the files matching the
+// filter in the query string.
+func (s *Server) handleGallery(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.parseFileFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		page, err = strconv.Atoi(p)
+		if err != nil || page < 1 {
+			http.Error(w, "Invalid page", http.StatusBadRequest)
+			return
+		}
+	}
+
+	total, err := s.db.CountMediaFiles(filter)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error counting media files: %v", err)
+		return
+	}
+	pages := max(1, (total+galleryPageSize-1)/galleryPageSize)
+	page = min(page, pages)
+
+	files, err := s.db.MediaFilesPage(filter, galleryPageSize, (page-1)*galleryPageSize)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching media files: %v", err)
+		return
+	}
+
+	items := make([]galleryItem, len(files))
+	for i, f := range files {
+		labels, err := s.db.AppliedLabels(db.MediaFileLabels, f.ID)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching labels for media file %d: %v", f.ID, err)
+			return
+		}
+		items[i] = galleryItem{File: f, Labels: labels}
+	}
+
+	allLabels, err := s.db.AllLabels()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching labels: %v", err)
+		return
+	}
+
+	data := galleryData{
+		Items:     items,
+		Total:     total,
+		Page:      page,
+		Pages:     pages,
+		Filter:    filter,
+		Query:     filterQuery(filter),
+		Statuses:  db.Statuses,
+		Reviews:   db.Reviews,
+		Queues:    db.Queues,
+		Types:     db.MediaTypes,
+		AllLabels: allLabels,
+	}
+	if page > 1 {
+		data.PrevURL = galleryURL(filter, page-1)
+	}
+	if page < pages {
+		data.NextURL = galleryURL(filter, page+1)
+	}
+
+	s.renderTemplate(w, "gallery.html", data)
+}
+
+// galleryURL returns the address of a gallery page of filtered files.
+func galleryURL(filter db.FileFilter, page int) string {
+	query := filterQuery(filter)
+	if page == 1 {
+		return "/gallery" + query
+	}
+	if query == "" {
+		return fmt.Sprintf("/gallery?page=%d", page)
+	}
+	return fmt.Sprintf("/gallery%s&page=%d", query, page)
+}
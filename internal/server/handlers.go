@@ -11,19 +11,105 @@ import (
 	"strings"
 
 	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/monorkin/just-label-it/internal/project"
 )
 
 // viewerData is the template data for the viewer page.
 type viewerData struct {
 	File      *db.MediaFile
-	Labels    []db.Label
+	Labels    []db.AppliedLabel
 	Keyframes []db.Keyframe
+	Segments  []db.Segment
+	Tracks    []db.Track
+	Spans     []db.Span
+	Text      string // Content of text files.
+	TextError string // Why the content of a text file isn't shown.
+	Fields    []fieldInput
+	Slots     []slotInput
+	Regions   []db.Region
+	Skeletons []db.Skeleton
 	Nav       *db.NavigationInfo
+	QueueOf   int // Files the queue is drawn from, when a queue is picked.
+	Filter    db.FileFilter
+	Query     string // Filter query appended to viewer links.
+	Statuses  []string
+	Reviews   []string
+	Queues    []string
+	Types     []string   // Media types offered by the filter.
+	AllLabels []db.Label // Label names suggested by the filter.
+	Progress  *db.Progress
+	Threads   []db.CommentThread
+	Review    *db.Review // Latest review of the file, if it has one.
+	Reviewer  bool       // Whether the user may approve and reject files.
+	Order     db.Order   // Order of navigation between files.
+	Orders    []string
+}
+
+// fieldInput is a project form field with its value on the viewed file.
+type fieldInput struct {
+	db.FieldDefinition
+	Value   string // Formatted for the input, empty if unset.
+	Checked bool   // Value of bool fields.
+}
+
+// slotInput is a project description slot with its text on the viewed file.
+type slotInput struct {
+	project.DescriptionSlot
+	Description string
+}
+
+// slotInputs pairs the project's description slots with a file's descriptions.
+func (s *Server) slotInputs(fileID int64) ([]slotInput, error) {
+	if len(s.project.Descriptions) == 0 {
+		return nil, nil
+	}
+
+	descriptions, err := s.db.NamedDescriptions(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]slotInput, len(s.project.Descriptions))
+	for i, slot := range s.project.Descriptions {
+		inputs[i] = slotInput{DescriptionSlot: slot, Description: descriptions[slot.Name]}
+	}
+	return inputs, nil
+}
+
+// fieldInputs pairs the project's form fields with a file's values.
+func (s *Server) fieldInputs(fileID int64) ([]fieldInput, error) {
+	if len(s.project.Fields) == 0 {
+		return nil, nil
+	}
+
+	values, err := s.db.FieldValuesForMediaFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]fieldInput, len(s.project.Fields))
+	for i, def := range s.project.Fields {
+		inputs[i] = fieldInput{FieldDefinition: def}
+		switch v := values[def.Name].(type) {
+		case nil:
+		case bool:
+			inputs[i].Checked = v
+		default:
+			inputs[i].Value = fmt.Sprint(v)
+		}
+	}
+	return inputs, nil
 }
 
 // handleIndex redirects to the first media file, or shows an empty state.
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
-	first, err := s.db.FirstMediaFile()
+	filter, err := s.parseFileFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	first, err := s.db.FirstMediaFile(filter)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		log.Printf("error fetching first media file: %v", err)
@@ -31,11 +117,11 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if first == nil {
-		s.renderTemplate(w, "viewer.html", nil)
+		s.renderTemplate(w, "viewer.html", &viewerData{Filter: filter, Query: filterQuery(filter)})
 		return
 	}
 
-	http.Redirect(w, r, fmt.Sprintf("/files/%d", first.ID), http.StatusFound)
+	http.Redirect(w, r, fmt.Sprintf("/files/%d%s", first.ID, filterQuery(filter)), http.StatusFound)
 }
 
 // handleViewFile renders the viewer page for a specific media file.
@@ -57,21 +143,52 @@ func (s *Server) handleViewFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	labels, err := s.db.LabelsForMediaFile(id)
+	labels, err := s.db.AppliedLabels(db.MediaFileLabels, id)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		log.Printf("error fetching labels for media file %d: %v", id, err)
 		return
 	}
 
-	nav, err := s.db.GetNavigation(id)
+	filter, err := s.parseFileFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	nav, err := s.db.GetNavigation(id, filter)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		log.Printf("error fetching navigation for media file %d: %v", id, err)
 		return
 	}
 
+	var queueOf int
+	if filter.Queue != "" {
+		queueOf, err = s.db.CountQueueScope(filter)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error counting media files: %v", err)
+			return
+		}
+	}
+
+	progress, err := s.db.Progress(db.FileFilter{})
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error computing progress: %v", err)
+		return
+	}
+
+	allLabels, err := s.db.AllLabels()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching labels: %v", err)
+		return
+	}
+
 	var keyframes []db.Keyframe
+	var segments []db.Segment
 	if file.MediaType == "video" || file.MediaType == "audio" {
 		if err := s.db.EnsurePinnedKeyframe(id); err != nil {
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -85,13 +202,133 @@ func (s *Server) handleViewFile(w http.ResponseWriter, r *http.Request) {
 			log.Printf("error fetching keyframes for media file %d: %v", id, err)
 			return
 		}
+
+		segments, err = s.db.SegmentsForMediaFile(id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching segments for media file %d: %v", id, err)
+			return
+		}
+	}
+
+	var tracks []db.Track
+	if file.MediaType == "video" {
+		tracks, err = s.db.TracksForMediaFile(id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching tracks for media file %d: %v", id, err)
+			return
+		}
+	}
+
+	var spans []db.Span
+	var text, textError string
+	if file.MediaType == "text" {
+		text, err = s.readText(file)
+		if errors.Is(err, errTextTooLarge) {
+			textError = fmt.Sprintf("This file is larger than %d KiB and can't be shown.", maxTextSize>>10)
+		} else if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error reading text of media file %d: %v", id, err)
+			return
+		}
+
+		spans, err = s.db.SpansForMediaFile(id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching spans for media file %d: %v", id, err)
+			return
+		}
+	}
+
+	fields, err := s.fieldInputs(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching fields for media file %d: %v", id, err)
+		return
+	}
+
+	slots, err := s.slotInputs(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching descriptions for media file %d: %v", id, err)
+		return
+	}
+
+	var regions []db.Region
+	var skeletons []db.Skeleton
+	if file.MediaType == "image" {
+		if err := s.ensureImageSize(file); err != nil {
+			log.Printf("warning: reading size of %s: %v", file.Path, err)
+		}
+
+		regions, err = s.db.RegionsForMediaFile(id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching regions for media file %d: %v", id, err)
+			return
+		}
+
+		skeletons, err = s.db.AllSkeletons()
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching skeletons: %v", err)
+			return
+		}
+	}
+
+	threads, err := s.db.CommentThreadsForMediaFile(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching comment threads for media file %d: %v", id, err)
+		return
+	}
+
+	var review *db.Review
+	if file.Review != "" {
+		review, err = s.db.LatestReview(id)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			log.Printf("error fetching review of media file %d: %v", id, err)
+			return
+		}
+	}
+
+	order, err := s.db.NavigationOrder()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching navigation order: %v", err)
+		return
 	}
 
 	s.renderTemplate(w, "viewer.html", viewerData{
 		File:      file,
 		Labels:    labels,
 		Keyframes: keyframes,
+		Segments:  segments,
+		Tracks:    tracks,
+		Spans:     spans,
+		Text:      text,
+		TextError: textError,
+		Fields:    fields,
+		Slots:     slots,
+		Regions:   regions,
+		Skeletons: skeletons,
 		Nav:       nav,
+		QueueOf:   queueOf,
+		Filter:    filter,
+		Query:     filterQuery(filter),
+		Statuses:  db.Statuses,
+		Reviews:   db.Reviews,
+		Queues:    db.Queues,
+		Types:     db.MediaTypes,
+		AllLabels: allLabels,
+		Progress:  progress,
+		Threads:   threads,
+		Review:    review,
+		Reviewer:  s.project.IsReviewer(s.user),
+		Order:     order,
+		Orders:    db.Orders,
 	})
 }
 
@@ -113,8 +350,8 @@ func (s *Server) handleServeMedia(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, absPath)
 }
 
-// handleAddFileLabel adds a label to a media file.
-func (s *Server) handleAddFileLabel(w http.ResponseWriter, r *http.Request) {
+// handleUpdateFileDescription updates a media file's description.
+func (s *Server) handleUpdateFileDescription(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r, "id")
 	if err != nil {
 		http.Error(w, "Invalid file ID", http.StatusBadRequest)
@@ -122,54 +359,92 @@ func (s *Server) handleAddFileLabel(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var body struct {
-		Name string `json:"name"`
+		Description string `json:"description"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	label, err := s.db.FindOrCreateLabel(strings.TrimSpace(body.Name))
-	if err != nil {
+	if err := s.db.UpdateDescription(id, body.Description); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		log.Printf("error creating label %q: %v", body.Name, err)
+		log.Printf("error updating description for media file %d: %v", id, err)
 		return
 	}
 
-	if err := s.db.AddMediaLabel(id, label.ID); err != nil {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUpdateFileNamedDescription updates a file's description in one of
+// the project's description slots.
+func (s *Server) handleUpdateFileNamedDescription(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	slot, ok := s.project.DescriptionSlot(r.PathValue("name"))
+	if !ok {
+		http.Error(w, "Description slot not found", http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.SetNamedDescription(id, slot.Name, body.Description); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		log.Printf("error adding label to media file %d: %v", id, err)
+		log.Printf("error updating %s description for media file %d: %v", slot.Name, id, err)
 		return
 	}
 
-	respondJSON(w, http.StatusCreated, label)
+	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleRemoveFileLabel removes a label from a media file.
-func (s *Server) handleRemoveFileLabel(w http.ResponseWriter, r *http.Request) {
-	fileID, err := parseID(r, "id")
+// handleUpdateFileField validates and stores the value of a project form
+// field on a media file. An empty value clears the field.
+func (s *Server) handleUpdateFileField(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
 	if err != nil {
 		http.Error(w, "Invalid file ID", http.StatusBadRequest)
 		return
 	}
 
-	labelID, err := parseID(r, "lid")
-	if err != nil {
-		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+	field, ok := s.project.Field(r.PathValue("name"))
+	if !ok {
+		http.Error(w, "Field not found", http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Value any `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.db.RemoveMediaLabel(fileID, labelID); err != nil {
+	if _, err := s.db.SetFieldValue(id, field, body.Value); err != nil {
+		if errors.Is(err, db.ErrInvalidField) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		log.Printf("error removing label %d from media file %d: %v", labelID, fileID, err)
+		log.Printf("error updating field %q of media file %d: %v", field.Name, id, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleUpdateFileDescription updates a media file's description.
-func (s *Server) handleUpdateFileDescription(w http.ResponseWriter, r *http.Request) {
+// handleUpdateFileStatus changes the workflow status of a file.
+func (s *Server) handleUpdateFileStatus(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r, "id")
 	if err != nil {
 		http.Error(w, "Invalid file ID", http.StatusBadRequest)
@@ -177,16 +452,83 @@ func (s *Server) handleUpdateFileDescription(w http.ResponseWriter, r *http.Requ
 	}
 
 	var body struct {
-		Description string `json:"description"`
+		Status string `json:"status"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.db.UpdateDescription(id, body.Description); err != nil {
+	if err := s.db.SetStatus(id, body.Status); err != nil {
+		if errors.Is(err, db.ErrInvalidStatus) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		log.Printf("error updating description for media file %d: %v", id, err)
+		log.Printf("error updating status of media file %d: %v", id, err)
+		return
+	}
+
+	progress, err := s.db.Progress(db.FileFilter{})
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error computing progress: %v", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, progressJSON(progress))
+}
+
+// progressJSON is the project progress as reported to the header.
+func progressJSON(p *db.Progress) map[string]any {
+	return map[string]any{
+		"finished": p.Finished(),
+		"total":    p.Total,
+		"percent":  p.FinishedPercent(),
+	}
+}
+
+// handleUpdateFileMetadata stores the pixel size and duration of a video or
+// audio file, which the browser reports once the media has loaded.
+func (s *Server) handleUpdateFileMetadata(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Width      *int   `json:"width"`
+		Height     *int   `json:"height"`
+		DurationMs *int64 `json:"duration_ms"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil ||
+		(body.Width != nil && *body.Width < 0) ||
+		(body.Height != nil && *body.Height < 0) ||
+		(body.DurationMs != nil && *body.DurationMs < 0) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	file, err := s.db.GetMediaFile(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching media file %d: %v", id, err)
+		return
+	}
+	if file == nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	if file.MediaType != "video" && file.MediaType != "audio" {
+		http.Error(w, "Only video and audio files report metadata", http.StatusBadRequest)
+		return
+	}
+
+	update := db.MetadataUpdate{Width: body.Width, Height: body.Height, DurationMs: body.DurationMs}
+	if err := s.db.SetMediaMetadata(id, update); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating metadata for media file %d: %v", id, err)
 		return
 	}
 
@@ -269,63 +611,34 @@ func (s *Server) handleDeleteKeyframe(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleAddKeyframeLabel adds a label to a keyframe.
-func (s *Server) handleAddKeyframeLabel(w http.ResponseWriter, r *http.Request) {
-	kfID, err := parseID(r, "id")
+// handleUpdateKeyframeDescription updates a keyframe's description.
+func (s *Server) handleUpdateKeyframeDescription(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
 	if err != nil {
 		http.Error(w, "Invalid keyframe ID", http.StatusBadRequest)
 		return
 	}
 
 	var body struct {
-		Name string `json:"name"`
+		Description string `json:"description"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" {
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	label, err := s.db.FindOrCreateLabel(strings.TrimSpace(body.Name))
-	if err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		log.Printf("error creating label %q: %v", body.Name, err)
-		return
-	}
-
-	if err := s.db.AddKeyframeLabel(kfID, label.ID); err != nil {
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		log.Printf("error adding label to keyframe %d: %v", kfID, err)
-		return
-	}
-
-	respondJSON(w, http.StatusCreated, label)
-}
-
-// handleRemoveKeyframeLabel removes a label from a keyframe.
-func (s *Server) handleRemoveKeyframeLabel(w http.ResponseWriter, r *http.Request) {
-	kfID, err := parseID(r, "id")
-	if err != nil {
-		http.Error(w, "Invalid keyframe ID", http.StatusBadRequest)
-		return
-	}
-
-	labelID, err := parseID(r, "lid")
-	if err != nil {
-		http.Error(w, "Invalid label ID", http.StatusBadRequest)
-		return
-	}
-
-	if err := s.db.RemoveKeyframeLabel(kfID, labelID); err != nil {
+	if err := s.db.UpdateKeyframeDescription(id, body.Description); err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		log.Printf("error removing label %d from keyframe %d: %v", labelID, kfID, err)
+		log.Printf("error updating description for keyframe %d: %v", id, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleUpdateKeyframeDescription updates a keyframe's description.
-func (s *Server) handleUpdateKeyframeDescription(w http.ResponseWriter, r *http.Request) {
+// handleUpdateKeyframeTranscript updates the transcript or language of a keyframe.
+// Only the fields present in the request body are changed.
+func (s *Server) handleUpdateKeyframeTranscript(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r, "id")
 	if err != nil {
 		http.Error(w, "Invalid keyframe ID", http.StatusBadRequest)
@@ -333,31 +646,40 @@ func (s *Server) handleUpdateKeyframeDescription(w http.ResponseWriter, r *http.
 	}
 
 	var body struct {
-		Description string `json:"description"`
+		Transcript *string `json:"transcript"`
+		Language   *string `json:"language"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if err := s.db.UpdateKeyframeDescription(id, body.Description); err != nil {
+	update := db.TranscriptUpdate{Transcript: body.Transcript, Language: body.Language}
+	if err := s.db.UpdateKeyframeTranscript(id, update); err != nil {
+		if errors.Is(err, db.ErrInvalidLanguage) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		log.Printf("error updating description for keyframe %d: %v", id, err)
+		log.Printf("error updating transcript for keyframe %d: %v", id, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
-// handleSearchLabels returns labels matching a query string.
+// handleSearchLabels returns label suggestions for a query string.
+// The optional file_id and prev_file_id parameters give the ranking context;
+// with an empty query, labels from the previous file are suggested.
 func (s *Server) handleSearchLabels(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
-	if query == "" {
-		respondJSON(w, http.StatusOK, []db.Label{})
-		return
-	}
+	params := r.URL.Query()
+	query := params.Get("q")
 
-	labels, err := s.db.SearchLabels(query)
+	opts := db.SearchOptions{Recent: s.recent.list()}
+	opts.FileID, _ = strconv.ParseInt(params.Get("file_id"), 10, 64)
+	opts.PrevFileID, _ = strconv.ParseInt(params.Get("prev_file_id"), 10, 64)
+
+	labels, err := s.db.SearchLabels(query, opts)
 	if err != nil {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		log.Printf("error searching labels for %q: %v", query, err)
@@ -373,8 +695,15 @@ func (s *Server) handleSearchLabels(w http.ResponseWriter, r *http.Request) {
 
 // renderTemplate executes a named template with the given data.
 func (s *Server) renderTemplate(w http.ResponseWriter, name string, data any) {
+	tmpl, ok := s.templates[name]
+	if !ok {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error rendering template %q: not found", name)
+		return
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := s.templates.ExecuteTemplate(w, name, data); err != nil {
+	if err := tmpl.ExecuteTemplate(w, name, data); err != nil {
 		log.Printf("error rendering template %q: %v", name, err)
 	}
 }
@@ -0,0 +1,355 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// labelData is the template data for the label guidelines page.
+type labelData struct {
+	Label      *db.Label
+	Examples   []db.MediaFile
+	Attributes []db.AttributeDefinition
+	Skeleton   *db.Skeleton
+}
+
+// handleListLabels renders the list of all labels with their definitions.
+func (s *Server) handleListLabels(w http.ResponseWriter, r *http.Request) {
+	labels, err := s.db.AllLabels()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching labels: %v", err)
+		return
+	}
+
+	s.renderTemplate(w, "labels.html", labels)
+}
+
+// handleViewLabel renders the guidelines page for a single label.
+func (s *Server) handleViewLabel(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	label, err := s.db.GetLabel(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching label %d: %v", id, err)
+		return
+	}
+	if label == nil {
+		http.Error(w, "Label not found", http.StatusNotFound)
+		return
+	}
+
+	examples, err := s.db.LabelExamples(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching examples for label %d: %v", id, err)
+		return
+	}
+
+	attributes, err := s.db.AttributeDefinitionsForLabel(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching attribute definitions for label %d: %v", id, err)
+		return
+	}
+
+	skeleton, err := s.db.LabelSkeleton(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching skeleton of label %d: %v", id, err)
+		return
+	}
+
+	s.renderTemplate(w, "label.html", labelData{
+		Label:      label,
+		Examples:   examples,
+		Attributes: attributes,
+		Skeleton:   skeleton,
+	})
+}
+
+// handleUpdateLabel updates a label's definition and example notes.
+// Only the fields present in the request body are changed.
+func (s *Server) handleUpdateLabel(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Definition    *string `json:"definition"`
+		PositiveNotes *string `json:"positive_notes"`
+		NegativeNotes *string `json:"negative_notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	label, err := s.db.GetLabel(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching label %d: %v", id, err)
+		return
+	}
+	if label == nil {
+		http.Error(w, "Label not found", http.StatusNotFound)
+		return
+	}
+
+	update := db.LabelUpdate{
+		Definition:    body.Definition,
+		PositiveNotes: body.PositiveNotes,
+		NegativeNotes: body.NegativeNotes,
+	}
+	if err := s.db.UpdateLabel(id, update); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating label %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAddLabelExample marks a media file, identified by its path, as an example of a label.
+func (s *Server) handleAddLabelExample(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Path string `json:"path"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Path) == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	file, err := s.db.GetMediaFileByPath(strings.TrimSpace(body.Path))
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching media file %q: %v", body.Path, err)
+		return
+	}
+	if file == nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	if err := s.db.AddLabelExample(id, file.ID); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error adding example to label %d: %v", id, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, file)
+}
+
+// handleRemoveLabelExample removes a media file from a label's examples.
+func (s *Server) handleRemoveLabelExample(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	fileID, err := parseID(r, "fid")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.RemoveLabelExample(id, fileID); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error removing example %d from label %d: %v", fileID, id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListAttributeDefinitions returns a label's attribute schema.
+func (s *Server) handleListAttributeDefinitions(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	defs, err := s.db.AttributeDefinitionsForLabel(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching attribute definitions for label %d: %v", id, err)
+		return
+	}
+
+	if defs == nil {
+		defs = []db.AttributeDefinition{}
+	}
+
+	respondJSON(w, http.StatusOK, defs)
+}
+
+// handleCreateAttributeDefinition adds an attribute to a label's schema.
+func (s *Server) handleCreateAttributeDefinition(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Name    string   `json:"name"`
+		Type    string   `json:"type"`
+		Options []string `json:"options"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	label, err := s.db.GetLabel(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching label %d: %v", id, err)
+		return
+	}
+	if label == nil {
+		http.Error(w, "Label not found", http.StatusNotFound)
+		return
+	}
+
+	def, err := s.db.CreateAttributeDefinition(id, body.Name, body.Type, body.Options)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidAttributes) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error creating attribute definition for label %d: %v", id, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, def)
+}
+
+// handleDeleteAttributeDefinition removes an attribute from a label's schema.
+func (s *Server) handleDeleteAttributeDefinition(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	attrID, err := parseID(r, "aid")
+	if err != nil {
+		http.Error(w, "Invalid attribute ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteAttributeDefinition(id, attrID); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error deleting attribute %d of label %d: %v", attrID, id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetSkeleton returns the skeleton template of a label.
+func (s *Server) handleGetSkeleton(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	skeleton, err := s.db.LabelSkeleton(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching skeleton of label %d: %v", id, err)
+		return
+	}
+	if skeleton == nil {
+		http.Error(w, "Skeleton not found", http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, skeleton)
+}
+
+// handleSetSkeleton creates or replaces the skeleton template of a label.
+func (s *Server) handleSetSkeleton(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Keypoints []string `json:"keypoints"`
+		Edges     [][2]int `json:"edges"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	label, err := s.db.GetLabel(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching label %d: %v", id, err)
+		return
+	}
+	if label == nil {
+		http.Error(w, "Label not found", http.StatusNotFound)
+		return
+	}
+
+	skeleton, err := s.db.SetLabelSkeleton(id, body.Keypoints, body.Edges)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidSkeleton) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error saving skeleton of label %d: %v", id, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, skeleton)
+}
+
+// handleDeleteSkeleton removes the skeleton template of a label.
+func (s *Server) handleDeleteSkeleton(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid label ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteLabelSkeleton(id); err != nil {
+		if errors.Is(err, db.ErrInvalidSkeleton) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error deleting skeleton of label %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"net/http"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// orderNames are the navigation order titles shown in the viewer.
+var orderNames = map[string]string{
+	db.OrderPath:         "By path",
+	db.OrderShuffle:      "Shuffled",
+	db.OrderModified:     "Oldest first",
+	db.OrderSize:         "Smallest first",
+	db.OrderDuration:     "Shortest first",
+	db.OrderLeastLabeled: "Least labeled first",
+}
+
+// handleUpdateOrder changes the order the project's files are navigated
+// in. Shuffling without a seed picks a new random one.
+func (s *Server) handleUpdateOrder(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Order string `json:"order"`
+		Seed  int64  `json:"seed"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	order := db.Order{Name: body.Order, Seed: body.Seed}
+	if order.Name == db.OrderShuffle && order.Seed == 0 {
+		order.Seed = rand.Int64N(1 << 31)
+	}
+
+	if err := s.db.SetNavigationOrder(order); err != nil {
+		if errors.Is(err, db.ErrInvalidOrder) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating navigation order: %v", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, order)
+}
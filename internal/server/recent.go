@@ -0,0 +1,36 @@
+package server
+
+import (
+	"slices"
+	"sync"
+)
+
+// maxRecentLabels caps how many labels the session remembers.
+const maxRecentLabels = 50
+
+// recentLabels remembers which labels were used during this server session,
+// most recent first, so label suggestions can favor them.
+type recentLabels struct {
+	mu  sync.Mutex
+	ids []int64
+}
+
+// touch moves a label to the front of the list.
+func (r *recentLabels) touch(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ids = slices.DeleteFunc(r.ids, func(existing int64) bool { return existing == id })
+	r.ids = slices.Insert(r.ids, 0, id)
+	if len(r.ids) > maxRecentLabels {
+		r.ids = r.ids[:maxRecentLabels]
+	}
+}
+
+// list returns a copy of the recently used label IDs, most recent first.
+func (r *recentLabels) list() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.ids)
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/monorkin/just-label-it/internal/imagesize"
+)
+
+// regionBody is the request body for creating or reshaping a region. Boxes
+// use x, y, width and height; polygons use points; masks use mask; keypoints
+// use label_id, naming the skeleton's label, and keypoints.
+type regionBody struct {
+	Kind      string       `json:"kind"`
+	LabelID   int64        `json:"label_id"`
+	Keypoints [][3]float64 `json:"keypoints"`
+	X         float64      `json:"x"`
+	Y         float64      `json:"y"`
+	Width     float64      `json:"width"`
+	Height    float64      `json:"height"`
+	Points    [][2]float64 `json:"points"`
+	Mask      struct {
+		Size   [2]int `json:"size"` // [height, width], as in COCO
+		Counts []int  `json:"counts"`
+	} `json:"mask"`
+}
+
+func (b regionBody) box() db.Box {
+	return db.Box{X: b.X, Y: b.Y, Width: b.Width, Height: b.Height}
+}
+
+func (b regionBody) polygon() db.Polygon {
+	points := make(db.Polygon, len(b.Points))
+	for i, p := range b.Points {
+		points[i] = db.Point{X: p[0], Y: p[1]}
+	}
+	return points
+}
+
+func (b regionBody) keypoints() db.Keypoints {
+	keypoints := make(db.Keypoints, len(b.Keypoints))
+	for i, k := range b.Keypoints {
+		keypoints[i] = db.Keypoint{X: k[0], Y: k[1], Visibility: int(k[2])}
+	}
+	return keypoints
+}
+
+func (b regionBody) mask() db.Mask {
+	return db.Mask{Width: b.Mask.Size[1], Height: b.Mask.Size[0], Counts: b.Mask.Counts}
+}
+
+// ensureImageSize reads and stores the pixel size of an image that doesn't
+// have one yet. Formats whose size can't be read, like SVG, are left without
+// one and only support box regions.
+func (s *Server) ensureImageSize(file *db.MediaFile) error {
+	if file.MediaType != "image" || file.Width > 0 {
+		return nil
+	}
+
+	width, height, err := imagesize.Read(filepath.Join(s.mediaRoot, file.Path))
+	if errors.Is(err, imagesize.ErrUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.db.SetImageSize(file.ID, width, height); err != nil {
+		return err
+	}
+
+	file.Width, file.Height = width, height
+	return nil
+}
+
+// handleListRegions returns all regions of a media file.
+func (s *Server) handleListRegions(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	regions, err := s.db.RegionsForMediaFile(fileID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching regions for media file %d: %v", fileID, err)
+		return
+	}
+
+	if regions == nil {
+		regions = []db.Region{}
+	}
+
+	respondJSON(w, http.StatusOK, regions)
+}
+
+// handleCreateRegion adds a region to a media file.
+func (s *Server) handleCreateRegion(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	var body regionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	file, err := s.db.GetMediaFile(fileID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching media file %d: %v", fileID, err)
+		return
+	}
+	if file == nil || file.MediaType != "image" {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	if err := s.ensureImageSize(file); err != nil {
+		log.Printf("warning: reading size of %s: %v", file.Path, err)
+	}
+
+	var region *db.Region
+	switch body.Kind {
+	case "", db.RegionBox:
+		region, err = s.db.CreateRegion(fileID, body.box())
+	case db.RegionPolygon:
+		region, err = s.db.CreatePolygonRegion(fileID, body.polygon())
+	case db.RegionMask:
+		region, err = s.db.CreateMaskRegion(fileID, body.mask())
+	case db.RegionKeypoints:
+		region, err = s.db.CreateKeypointsRegion(fileID, body.LabelID, body.keypoints())
+	default:
+		http.Error(w, "Unknown region kind", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidRegion) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error creating region for media file %d: %v", fileID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, region)
+}
+
+// handleUpdateRegion reshapes a region according to its kind and responds
+// with the stored region, whose vertices may have been snapped.
+func (s *Server) handleUpdateRegion(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid region ID", http.StatusBadRequest)
+		return
+	}
+
+	var body regionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	region, err := s.db.GetRegion(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching region %d: %v", id, err)
+		return
+	}
+	if region == nil {
+		http.Error(w, "Region not found", http.StatusNotFound)
+		return
+	}
+
+	switch region.Kind {
+	case db.RegionPolygon:
+		err = s.db.UpdateRegionPolygon(id, body.polygon())
+	case db.RegionMask:
+		err = s.db.UpdateRegionMask(id, body.mask())
+	case db.RegionKeypoints:
+		err = s.db.UpdateRegionKeypoints(id, body.keypoints())
+	default:
+		err = s.db.UpdateRegionBox(id, body.box())
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidRegion) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating region %d: %v", id, err)
+		return
+	}
+
+	region, err = s.db.GetRegion(id)
+	if err != nil || region == nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching region %d: %v", id, err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, region)
+}
+
+// handleDeleteRegion deletes a region.
+func (s *Server) handleDeleteRegion(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid region ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteRegion(id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error deleting region %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUpdateRegionDescription updates a region's description.
+func (s *Server) handleUpdateRegionDescription(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid region ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.UpdateRegionDescription(id, body.Description); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating description for region %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// reviewHistoryLimit is the most past reviews the review page lists.
+const reviewHistoryLimit = 50
+
+// reviewData is the template data for the review queue page.
+type reviewData struct {
+	Pending  []db.MediaFile // Files marked done that await a decision, in navigation order.
+	Query    string         // Filter query that walks the viewer through Pending.
+	Reviews  []db.Review    // Latest decisions, newest first.
+	User     string
+	Reviewer bool
+}
+
+// handleReviewQueue renders the files awaiting review and the latest
+// decisions.
+func (s *Server) handleReviewQueue(w http.ResponseWriter, r *http.Request) {
+	filter := db.FileFilter{Review: db.ReviewPending}
+	pending, err := s.db.MediaFilesPage(filter, -1, 0)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching files awaiting review: %v", err)
+		return
+	}
+
+	reviews, err := s.db.RecentReviews(reviewHistoryLimit)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching recent reviews: %v", err)
+		return
+	}
+
+	s.renderTemplate(w, "review.html", reviewData{
+		Pending:  pending,
+		Query:    filterQuery(filter),
+		Reviews:  reviews,
+		User:     s.user,
+		Reviewer: s.project.IsReviewer(s.user),
+	})
+}
+
+// handleReviewFile approves a file marked done, or rejects it back to the
+// annotators with a reason.
+func (s *Server) handleReviewFile(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	if !s.project.IsReviewer(s.user) {
+		http.Error(w, "Only the reviewers listed in jli.json can review files", http.StatusForbidden)
+		return
+	}
+
+	var body struct {
+		Decision string `json:"decision"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	file, err := s.db.GetMediaFile(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching media file %d: %v", id, err)
+		return
+	}
+	if file == nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	review, err := s.db.ReviewMediaFile(id, s.user, body.Decision, body.Reason)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidReview) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error reviewing media file %d: %v", id, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, review)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// searchLimit is the most results a description search returns.
+const searchLimit = 100
+
+// searchData is the template data for the search page.
+type searchData struct {
+	Query   string
+	Results []db.SearchResult
+	Error   string // Why the query couldn't be parsed.
+}
+
+// isQueryError reports whether a search failed because of its query.
+func isQueryError(err error) bool {
+	return errors.Is(err, db.ErrInvalidQuery)
+}
+
+// handleSearch renders the description search page.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+
+	results, err := s.db.SearchDescriptions(query, s.project.Fields, searchLimit)
+	if err != nil {
+		if isQueryError(err) {
+			s.renderTemplate(w, "search.html", searchData{Query: query, Error: err.Error()})
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error searching descriptions for %q: %v", query, err)
+		return
+	}
+
+	s.renderTemplate(w, "search.html", searchData{Query: query, Results: results})
+}
+
+// handleSearchDescriptions returns files and keyframes whose descriptions
+// match the query as JSON.
+func (s *Server) handleSearchDescriptions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+
+	results, err := s.db.SearchDescriptions(query, s.project.Fields, searchLimit)
+	if err != nil {
+		if isQueryError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error searching descriptions for %q: %v", query, err)
+		return
+	}
+
+	if results == nil {
+		results = []db.SearchResult{}
+	}
+
+	respondJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// segmentBody is the request body for creating, moving or resizing a segment.
+type segmentBody struct {
+	StartMs int64 `json:"start_ms"`
+	EndMs   int64 `json:"end_ms"`
+}
+
+// handleCreateSegment adds a time-range segment to a media file.
+func (s *Server) handleCreateSegment(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	var body segmentBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	file, err := s.db.GetMediaFile(fileID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching media file %d: %v", fileID, err)
+		return
+	}
+	if file == nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	if file.MediaType != "video" && file.MediaType != "audio" {
+		http.Error(w, "invalid segment: only video and audio files have segments", http.StatusBadRequest)
+		return
+	}
+
+	seg, err := s.db.CreateSegment(fileID, body.StartMs, body.EndMs)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidSegment) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error creating segment for media file %d: %v", fileID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, seg)
+}
+
+// handleUpdateSegment moves or resizes a segment.
+func (s *Server) handleUpdateSegment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid segment ID", http.StatusBadRequest)
+		return
+	}
+
+	var body segmentBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.UpdateSegmentRange(id, body.StartMs, body.EndMs); err != nil {
+		if errors.Is(err, db.ErrInvalidSegment) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating segment %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDeleteSegment deletes a segment.
+func (s *Server) handleDeleteSegment(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid segment ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteSegment(id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error deleting segment %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUpdateSegmentDescription updates a segment's description.
+func (s *Server) handleUpdateSegmentDescription(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid segment ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.UpdateSegmentDescription(id, body.Description); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating description for segment %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUpdateSegmentTranscript updates the transcript or language of a segment.
+// Only the fields present in the request body are changed.
+func (s *Server) handleUpdateSegmentTranscript(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid segment ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Transcript *string `json:"transcript"`
+		Language   *string `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	update := db.TranscriptUpdate{Transcript: body.Transcript, Language: body.Language}
+	if err := s.db.UpdateSegmentTranscript(id, update); err != nil {
+		if errors.Is(err, db.ErrInvalidLanguage) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating transcript for segment %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
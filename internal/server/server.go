@@ -2,31 +2,39 @@ package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"html/template"
 	"io/fs"
 	"log"
 	"net/http"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/monorkin/just-label-it/internal/db"
+	"github.com/monorkin/just-label-it/internal/project"
 	"github.com/monorkin/just-label-it/web"
 )
 
 // Server holds the dependencies for all HTTP handlers.
 type Server struct {
 	db        *db.DB
-	templates *template.Template
+	templates map[string]*template.Template
 	mediaRoot string
+	project   *project.Config
+	user      string // Annotator recorded with judgments, comments and reviews.
+	recent    *recentLabels
 }
 
 // New creates a Server and returns a configured http.Handler.
-func New(database *db.DB, mediaRoot string) (http.Handler, error) {
+func New(database *db.DB, mediaRoot string, cfg *project.Config, user string) (http.Handler, error) {
 	absRoot, err := filepath.Abs(mediaRoot)
 	if err != nil {
 		return nil, err
 	}
 
-	tmpl, err := template.New("").Funcs(templateFuncs()).ParseFS(web.Templates, "templates/*.html")
+	tmpl, err := parseTemplates()
 	if err != nil {
 		return nil, err
 	}
@@ -35,6 +43,9 @@ func New(database *db.DB, mediaRoot string) (http.Handler, error) {
 		db:        database,
 		templates: tmpl,
 		mediaRoot: absRoot,
+		project:   cfg,
+		user:      user,
+		recent:    &recentLabels{},
 	}
 
 	mux := http.NewServeMux()
@@ -47,6 +58,31 @@ func New(database *db.DB, mediaRoot string) (http.Handler, error) {
 	return handler, nil
 }
 
+// parseTemplates parses each page template together with the shared layout.
+// Pages each define their own "content" block, so they can't share one set.
+func parseTemplates() (map[string]*template.Template, error) {
+	pages, err := fs.Glob(web.Templates, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]*template.Template)
+	for _, page := range pages {
+		name := filepath.Base(page)
+		if name == "layout.html" {
+			continue
+		}
+
+		tmpl, err := template.New(name).Funcs(templateFuncs()).ParseFS(web.Templates, "templates/layout.html", page)
+		if err != nil {
+			return nil, err
+		}
+		templates[name] = tmpl
+	}
+
+	return templates, nil
+}
+
 func (s *Server) routes(mux *http.ServeMux) {
 	// Static assets (embedded).
 	staticFS, err := fs.Sub(web.Static, "static")
@@ -58,16 +94,35 @@ func (s *Server) routes(mux *http.ServeMux) {
 	// Pages.
 	mux.HandleFunc("GET /{$}", s.handleIndex)
 	mux.HandleFunc("GET /files/{id}", s.handleViewFile)
+	mux.HandleFunc("GET /labels", s.handleListLabels)
+	mux.HandleFunc("GET /labels/{id}", s.handleViewLabel)
+	mux.HandleFunc("GET /stats", s.handleStats)
+	mux.HandleFunc("GET /compare", s.handleCompare)
+	mux.HandleFunc("GET /gallery", s.handleGallery)
+	mux.HandleFunc("GET /search", s.handleSearch)
+	mux.HandleFunc("GET /comments", s.handleComments)
+	mux.HandleFunc("GET /review", s.handleReviewQueue)
 
 	// Media file serving.
 	mux.HandleFunc("GET /media/{path...}", s.handleServeMedia)
 
 	// File labels.
-	mux.HandleFunc("POST /files/{id}/labels", s.handleAddFileLabel)
-	mux.HandleFunc("DELETE /files/{id}/labels/{lid}", s.handleRemoveFileLabel)
+	mux.HandleFunc("POST /files/{id}/labels", s.handleAddAppliedLabel(db.MediaFileLabels))
+	mux.HandleFunc("PUT /files/{id}/labels/{lid}", s.handleUpdateAppliedLabel(db.MediaFileLabels))
+	mux.HandleFunc("DELETE /files/{id}/labels/{lid}", s.handleRemoveAppliedLabel(db.MediaFileLabels))
 
 	// File description.
 	mux.HandleFunc("PUT /files/{id}/description", s.handleUpdateFileDescription)
+	mux.HandleFunc("PUT /files/{id}/descriptions/{name}", s.handleUpdateFileNamedDescription)
+
+	// File form fields, declared in the project configuration.
+	mux.HandleFunc("PUT /files/{id}/fields/{name}", s.handleUpdateFileField)
+
+	// File workflow status.
+	mux.HandleFunc("PUT /files/{id}/status", s.handleUpdateFileStatus)
+
+	// File metadata, reported by the browser for video and audio.
+	mux.HandleFunc("PUT /files/{id}/metadata", s.handleUpdateFileMetadata)
 
 	// Keyframes.
 	mux.HandleFunc("POST /files/{id}/keyframes", s.handleCreateKeyframe)
@@ -75,14 +130,97 @@ func (s *Server) routes(mux *http.ServeMux) {
 	mux.HandleFunc("DELETE /keyframes/{id}", s.handleDeleteKeyframe)
 
 	// Keyframe labels.
-	mux.HandleFunc("POST /keyframes/{id}/labels", s.handleAddKeyframeLabel)
-	mux.HandleFunc("DELETE /keyframes/{id}/labels/{lid}", s.handleRemoveKeyframeLabel)
+	mux.HandleFunc("POST /keyframes/{id}/labels", s.handleAddAppliedLabel(db.KeyframeLabels))
+	mux.HandleFunc("PUT /keyframes/{id}/labels/{lid}", s.handleUpdateAppliedLabel(db.KeyframeLabels))
+	mux.HandleFunc("DELETE /keyframes/{id}/labels/{lid}", s.handleRemoveAppliedLabel(db.KeyframeLabels))
 
-	// Keyframe description.
+	// Keyframe description and transcript.
 	mux.HandleFunc("PUT /keyframes/{id}/description", s.handleUpdateKeyframeDescription)
+	mux.HandleFunc("PUT /keyframes/{id}/transcript", s.handleUpdateKeyframeTranscript)
+
+	// Segments.
+	mux.HandleFunc("POST /files/{id}/segments", s.handleCreateSegment)
+	mux.HandleFunc("PUT /segments/{id}", s.handleUpdateSegment)
+	mux.HandleFunc("DELETE /segments/{id}", s.handleDeleteSegment)
+
+	// Segment labels.
+	mux.HandleFunc("POST /segments/{id}/labels", s.handleAddAppliedLabel(db.SegmentLabels))
+	mux.HandleFunc("PUT /segments/{id}/labels/{lid}", s.handleUpdateAppliedLabel(db.SegmentLabels))
+	mux.HandleFunc("DELETE /segments/{id}/labels/{lid}", s.handleRemoveAppliedLabel(db.SegmentLabels))
+
+	// Segment description and transcript.
+	mux.HandleFunc("PUT /segments/{id}/description", s.handleUpdateSegmentDescription)
+	mux.HandleFunc("PUT /segments/{id}/transcript", s.handleUpdateSegmentTranscript)
+
+	// Spans.
+	mux.HandleFunc("POST /files/{id}/spans", s.handleCreateSpan)
+	mux.HandleFunc("DELETE /spans/{id}", s.handleDeleteSpan)
+
+	// Span labels.
+	mux.HandleFunc("POST /spans/{id}/labels", s.handleAddAppliedLabel(db.SpanLabels))
+	mux.HandleFunc("PUT /spans/{id}/labels/{lid}", s.handleUpdateAppliedLabel(db.SpanLabels))
+	mux.HandleFunc("DELETE /spans/{id}/labels/{lid}", s.handleRemoveAppliedLabel(db.SpanLabels))
+
+	// Span description.
+	mux.HandleFunc("PUT /spans/{id}/description", s.handleUpdateSpanDescription)
+
+	// Tracks.
+	mux.HandleFunc("POST /files/{id}/tracks", s.handleCreateTrack)
+	mux.HandleFunc("PUT /tracks/{id}", s.handleUpdateTrack)
+	mux.HandleFunc("DELETE /tracks/{id}", s.handleDeleteTrack)
+	mux.HandleFunc("PUT /tracks/{id}/boxes/{ms}", s.handleSetTrackBox)
+	mux.HandleFunc("DELETE /tracks/{id}/boxes/{ms}", s.handleDeleteTrackBox)
+
+	// Regions.
+	mux.HandleFunc("GET /files/{id}/regions", s.handleListRegions)
+	mux.HandleFunc("POST /files/{id}/regions", s.handleCreateRegion)
+	mux.HandleFunc("PUT /regions/{id}", s.handleUpdateRegion)
+	mux.HandleFunc("DELETE /regions/{id}", s.handleDeleteRegion)
+
+	// Region labels.
+	mux.HandleFunc("POST /regions/{id}/labels", s.handleAddAppliedLabel(db.RegionLabels))
+	mux.HandleFunc("PUT /regions/{id}/labels/{lid}", s.handleUpdateAppliedLabel(db.RegionLabels))
+	mux.HandleFunc("DELETE /regions/{id}/labels/{lid}", s.handleRemoveAppliedLabel(db.RegionLabels))
+
+	// Region description.
+	mux.HandleFunc("PUT /regions/{id}/description", s.handleUpdateRegionDescription)
+
+	// Label guidelines.
+	mux.HandleFunc("PUT /labels/{id}", s.handleUpdateLabel)
+	mux.HandleFunc("POST /labels/{id}/examples", s.handleAddLabelExample)
+	mux.HandleFunc("DELETE /labels/{id}/examples/{fid}", s.handleRemoveLabelExample)
+
+	// Label attribute schema.
+	mux.HandleFunc("GET /labels/{id}/attributes", s.handleListAttributeDefinitions)
+	mux.HandleFunc("POST /labels/{id}/attributes", s.handleCreateAttributeDefinition)
+	mux.HandleFunc("DELETE /labels/{id}/attributes/{aid}", s.handleDeleteAttributeDefinition)
+	mux.HandleFunc("GET /labels/{id}/skeleton", s.handleGetSkeleton)
+	mux.HandleFunc("PUT /labels/{id}/skeleton", s.handleSetSkeleton)
+	mux.HandleFunc("DELETE /labels/{id}/skeleton", s.handleDeleteSkeleton)
+
+	// Bulk edits of many files at once.
+	mux.HandleFunc("POST /bulk/preview", s.handleBulkPreview)
+	mux.HandleFunc("POST /bulk", s.handleBulkEdit)
+
+	// Navigation order of the project.
+	mux.HandleFunc("PUT /order", s.handleUpdateOrder)
+
+	// Review decisions.
+	mux.HandleFunc("POST /files/{id}/review", s.handleReviewFile)
+
+	// Comment threads on files and keyframes.
+	mux.HandleFunc("POST /files/{id}/comments", s.handleCreateCommentThread)
+	mux.HandleFunc("POST /threads/{id}/comments", s.handleAddComment)
+	mux.HandleFunc("PUT /threads/{id}/resolved", s.handleUpdateCommentThreadResolved)
+
+	// Pairwise comparisons.
+	mux.HandleFunc("POST /comparisons", s.handleCreateComparison)
 
 	// Label search API.
 	mux.HandleFunc("GET /api/labels", s.handleSearchLabels)
+
+	// Description search API.
+	mux.HandleFunc("GET /api/search", s.handleSearchDescriptions)
 }
 
 func templateFuncs() template.FuncMap {
@@ -90,15 +228,109 @@ func templateFuncs() template.FuncMap {
 		"isVideo": func(mediaType string) bool { return mediaType == "video" },
 		"isAudio": func(mediaType string) bool { return mediaType == "audio" },
 		"isImage": func(mediaType string) bool { return mediaType == "image" },
+		"isText":  func(mediaType string) bool { return mediaType == "text" },
 		"isTemporal": func(mediaType string) bool {
 			return mediaType == "video" || mediaType == "audio"
 		},
-		"labelsJSON": func(labels []db.Label) string {
+		"labelsJSON": func(labels []db.AppliedLabel) string {
 			if labels == nil {
 				return "[]"
 			}
 			b, _ := json.Marshal(labels)
 			return string(b)
 		},
+		"attributesJSON": func(attrs db.Attributes) string {
+			if attrs == nil {
+				return "{}"
+			}
+			b, _ := json.Marshal(attrs)
+			return string(b)
+		},
+		"pointsJSON": func(points db.Polygon) string {
+			if points == nil {
+				return "[]"
+			}
+			b, _ := json.Marshal(points)
+			return string(b)
+		},
+		"keypointsJSON": func(keypoints db.Keypoints) string {
+			if keypoints == nil {
+				return "[]"
+			}
+			b, _ := json.Marshal(keypoints)
+			return string(b)
+		},
+		"skeletonsJSON": func(skeletons []db.Skeleton) string {
+			if skeletons == nil {
+				return "[]"
+			}
+			b, _ := json.Marshal(skeletons)
+			return string(b)
+		},
+		"tracksJSON": func(tracks []db.Track) string {
+			if tracks == nil {
+				return "[]"
+			}
+			b, _ := json.Marshal(tracks)
+			return string(b)
+		},
+		"spansJSON": func(spans []db.Span) string {
+			if spans == nil {
+				return "[]"
+			}
+			b, _ := json.Marshal(spans)
+			return string(b)
+		},
+		"maskJSON": func(mask db.Mask) string {
+			if mask.IsZero() {
+				return ""
+			}
+			b, _ := json.Marshal(mask)
+			return string(b)
+		},
+		"attributesText": formatAttributes,
+		"statusName":     statusName,
+		"timestamp":      formatTimestamp,
+		"date":           func(t time.Time) string { return t.Local().Format("2006-01-02 15:04") },
+		"queueName":      func(queue string) string { return queueNames[queue] },
+		"reviewName":     func(review string) string { return reviewNames[review] },
+		"orderName":      func(order string) string { return orderNames[order] },
+		"barWidth": func(n, total int) float64 {
+			if total == 0 {
+				return 0
+			}
+			return float64(n) * 100 / float64(total)
+		},
+	}
+}
+
+// formatTimestamp renders milliseconds as "m:ss.mmm", like the timeline.
+func formatTimestamp(ms int64) string {
+	return fmt.Sprintf("%d:%02d.%03d", ms/60000, ms/1000%60, ms%1000)
+}
+
+// statusName turns a workflow status into a readable name, e.g.
+// "in_progress" into "In progress".
+func statusName(status string) string {
+	if status == "" {
+		return ""
+	}
+	name := strings.ReplaceAll(status, "_", " ")
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// formatAttributes renders attributes as "key=value" pairs sorted by key,
+// matching how the label chips display them in the browser.
+func formatAttributes(attrs db.Attributes) string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", k, attrs[k])
 	}
+	return strings.Join(parts, ", ")
 }
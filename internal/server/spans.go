@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// maxTextSize caps the size of text files shown in the viewer, since the
+// whole document is rendered into the page.
+const maxTextSize = 1 << 20
+
+// errTextTooLarge is returned by readText for files over maxTextSize.
+var errTextTooLarge = errors.New("text file too large")
+
+// readText reads the content of a text file.
+func (s *Server) readText(file *db.MediaFile) (string, error) {
+	path := filepath.Join(s.mediaRoot, file.Path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > maxTextSize {
+		return "", fmt.Errorf("%w: %s is %d bytes", errTextTooLarge, file.Path, info.Size())
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// spanBody is the request body for creating a span. Offsets count Unicode
+// code points, the end is exclusive.
+type spanBody struct {
+	StartOffset int `json:"start_offset"`
+	EndOffset   int `json:"end_offset"`
+}
+
+// handleCreateSpan adds a character-range span to a text file.
+func (s *Server) handleCreateSpan(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	var body spanBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	file, err := s.db.GetMediaFile(fileID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching media file %d: %v", fileID, err)
+		return
+	}
+	if file == nil || file.MediaType != "text" {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+
+	text, err := s.readText(file)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error reading text of media file %d: %v", fileID, err)
+		return
+	}
+	if body.EndOffset > utf8.RuneCountInString(text) {
+		http.Error(w, "invalid span: span must end within the text", http.StatusBadRequest)
+		return
+	}
+
+	span, err := s.db.CreateSpan(fileID, body.StartOffset, body.EndOffset)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidSpan) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error creating span for media file %d: %v", fileID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, span)
+}
+
+// handleDeleteSpan deletes a span.
+func (s *Server) handleDeleteSpan(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid span ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteSpan(id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error deleting span %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleUpdateSpanDescription updates a span's description.
+func (s *Server) handleUpdateSpanDescription(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid span ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.UpdateSpanDescription(id, body.Description); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating description for span %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
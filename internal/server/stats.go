@@ -0,0 +1,61 @@
+package server
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// matrixLimit caps how many labels appear in the co-occurrence matrix, so
+// the page stays readable on projects with many labels.
+const matrixLimit = 20
+
+// statsData is the template data for the statistics page.
+type statsData struct {
+	Stats     *db.Stats
+	Matrix    db.Matrix
+	Filter    db.FileFilter
+	Query     string // Filter query appended to viewer links.
+	Statuses  []string
+	Reviews   []string
+	Queues    []string
+	Types     []string
+	AllLabels []db.Label
+}
+
+// handleStats renders label statistics for the project, or for the files
+// matching the filter in the query string.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	filter, err := s.parseFileFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := s.db.Stats(filter)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error computing stats: %v", err)
+		return
+	}
+
+	allLabels, err := s.db.AllLabels()
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching labels: %v", err)
+		return
+	}
+
+	s.renderTemplate(w, "stats.html", statsData{
+		Stats:     stats,
+		Matrix:    stats.CooccurrenceMatrix(matrixLimit),
+		Filter:    filter,
+		Query:     filterQuery(filter),
+		Statuses:  db.Statuses,
+		Reviews:   db.Reviews,
+		Queues:    db.Queues,
+		Types:     db.MediaTypes,
+		AllLabels: allLabels,
+	})
+}
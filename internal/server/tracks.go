@@ -0,0 +1,196 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/monorkin/just-label-it/internal/db"
+)
+
+// trackBody is the request body for creating or renaming a track.
+type trackBody struct {
+	Label string `json:"label"`
+	Name  string `json:"name"`
+}
+
+// handleCreateTrack adds a track to a video, creating its label if needed.
+func (s *Server) handleCreateTrack(w http.ResponseWriter, r *http.Request) {
+	fileID, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	var body trackBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Label) == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	file, err := s.db.GetMediaFile(fileID)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching media file %d: %v", fileID, err)
+		return
+	}
+	if file == nil {
+		http.Error(w, "File not found", http.StatusNotFound)
+		return
+	}
+	if file.MediaType != "video" {
+		http.Error(w, "Only video files have tracks", http.StatusBadRequest)
+		return
+	}
+
+	label, err := s.db.FindOrCreateLabel(strings.TrimSpace(body.Label))
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error creating label %q: %v", body.Label, err)
+		return
+	}
+	s.recent.touch(label.ID)
+
+	track, err := s.db.CreateTrack(fileID, label.ID, strings.TrimSpace(body.Name))
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error creating track for media file %d: %v", fileID, err)
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, track)
+}
+
+// handleUpdateTrack changes the label and name of a track.
+func (s *Server) handleUpdateTrack(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid track ID", http.StatusBadRequest)
+		return
+	}
+
+	var body trackBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || strings.TrimSpace(body.Label) == "" {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	label, err := s.db.FindOrCreateLabel(strings.TrimSpace(body.Label))
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error creating label %q: %v", body.Label, err)
+		return
+	}
+
+	if err := s.db.UpdateTrack(id, label.ID, strings.TrimSpace(body.Name)); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error updating track %d: %v", id, err)
+		return
+	}
+
+	s.respondTrack(w, id)
+}
+
+// handleDeleteTrack deletes a track and all of its boxes.
+func (s *Server) handleDeleteTrack(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid track ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteTrack(id); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error deleting track %d: %v", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSetTrackBox sets the box of a track at the timestamp in the URL
+// and responds with the track.
+func (s *Server) handleSetTrackBox(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid track ID", http.StatusBadRequest)
+		return
+	}
+
+	ms, err := strconv.ParseInt(r.PathValue("ms"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid timestamp", http.StatusBadRequest)
+		return
+	}
+
+	var body regionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	track, err := s.db.GetTrack(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching track %d: %v", id, err)
+		return
+	}
+	if track == nil {
+		http.Error(w, "Track not found", http.StatusNotFound)
+		return
+	}
+
+	if err := s.db.SetTrackBox(id, ms, body.box()); err != nil {
+		if errors.Is(err, db.ErrInvalidRegion) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error setting box of track %d: %v", id, err)
+		return
+	}
+
+	s.respondTrack(w, id)
+}
+
+// handleDeleteTrackBox removes the box of a track at the timestamp in the
+// URL and responds with the track.
+func (s *Server) handleDeleteTrackBox(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r, "id")
+	if err != nil {
+		http.Error(w, "Invalid track ID", http.StatusBadRequest)
+		return
+	}
+
+	ms, err := strconv.ParseInt(r.PathValue("ms"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid timestamp", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.DeleteTrackBox(id, ms); err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error deleting box of track %d: %v", id, err)
+		return
+	}
+
+	s.respondTrack(w, id)
+}
+
+func (s *Server) respondTrack(w http.ResponseWriter, id int64) {
+	track, err := s.db.GetTrack(id)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		log.Printf("error fetching track %d: %v", id, err)
+		return
+	}
+	if track == nil {
+		http.Error(w, "Track not found", http.StatusNotFound)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, track)
+}
@@ -0,0 +1,61 @@
+(() => {
+  const { Controller } = Stimulus
+
+  class AttributeSchemaController extends Controller {
+    static targets = ["list", "name", "type", "options", "error"]
+    static values = { url: String }
+
+    add() {
+      const name = this.nameTarget.value.trim()
+      if (!name) return
+
+      const options = this.optionsTarget.value.split(",").map(o => o.trim()).filter(o => o)
+
+      fetch(this.urlValue, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ name, type: this.typeTarget.value, options })
+      }).then(r => {
+        if (!r.ok) {
+          return r.text().then(message => {
+            this.errorTarget.textContent = message.trim()
+          })
+        }
+        return r.json().then(def => {
+          this.errorTarget.textContent = ""
+          this.nameTarget.value = ""
+          this.optionsTarget.value = ""
+          this.#appendDefinition(def)
+        })
+      })
+    }
+
+    remove(event) {
+      const id = event.currentTarget.dataset.attributeId
+      const item = event.currentTarget.closest(".attribute-item")
+
+      fetch(`${this.urlValue}/${id}`, { method: "DELETE" }).then(r => {
+        if (r.ok && item) item.remove()
+      })
+    }
+
+    #appendDefinition(def) {
+      const type = def.Options.length > 0 ? `${def.Type}: ${def.Options.join(" | ")}` : def.Type
+
+      const item = document.createElement("li")
+      item.className = "attribute-item"
+      item.innerHTML = `<span class="attribute-name">${this.#escapeHtml(def.Name)}</span> ` +
+        `<span class="attribute-type">${this.#escapeHtml(type)}</span> ` +
+        `<button class="label-remove" data-action="click->attribute-schema#remove" data-attribute-id="${def.ID}">&times;</button>`
+      this.listTarget.appendChild(item)
+    }
+
+    #escapeHtml(str) {
+      const div = document.createElement("div")
+      div.textContent = str
+      return div.innerHTML
+    }
+  }
+
+  window.StimulusApp.register("attribute-schema", AttributeSchemaController)
+})()
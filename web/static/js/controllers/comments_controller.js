@@ -0,0 +1,48 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Starts, answers and resolves the review comment threads of a file, then
+  // reloads the viewer to show them.
+  class CommentsController extends Controller {
+    static targets = ["error"]
+    static values = { fileId: Number }
+
+    create(event) {
+      event.preventDefault()
+      const form = event.currentTarget
+      const data = new FormData(form)
+      const body = {
+        keyframe_id: parseInt(data.get("keyframe_id") || "0"),
+        body: data.get("body")
+      }
+      this.#send("POST", `/files/${this.fileIdValue}/comments`, body)
+    }
+
+    reply(event) {
+      event.preventDefault()
+      const form = event.currentTarget
+      const body = { body: new FormData(form).get("body") }
+      this.#send("POST", `/threads/${form.dataset.threadId}/comments`, body)
+    }
+
+    toggleResolved(event) {
+      const { threadId, resolved } = event.currentTarget.dataset
+      this.#send("PUT", `/threads/${threadId}/resolved`, { resolved: resolved !== "true" })
+    }
+
+    #send(method, url, body) {
+      fetch(url, {
+        method,
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body)
+      })
+        .then(async r => {
+          if (!r.ok) throw new Error((await r.text()).trim())
+          window.location.reload()
+        })
+        .catch(error => { this.errorTarget.textContent = error.message })
+    }
+  }
+
+  window.StimulusApp.register("comments", CommentsController)
+})()
@@ -0,0 +1,54 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Keys that record a judgment, mapped to the winner they choose.
+  const KEYS = { ArrowLeft: "left", ArrowRight: "right", t: "tie", T: "tie" }
+
+  class ComparisonController extends Controller {
+    static targets = ["reason", "error"]
+    static values = { leftId: Number, rightId: Number }
+
+    #saving = false
+
+    judge(event) {
+      this.#record(event.currentTarget.dataset.winner)
+    }
+
+    keydown(event) {
+      // Don't judge when typing in an input or textarea.
+      const tag = event.target.tagName
+      if (tag === "INPUT" || tag === "TEXTAREA") return
+
+      const winner = KEYS[event.key]
+      if (!winner) return
+      event.preventDefault()
+      this.#record(winner)
+    }
+
+    // Saves the judgment and loads the next pair.
+    #record(winner) {
+      if (this.#saving) return
+      this.#saving = true
+
+      fetch("/comparisons", {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({
+          left_id: this.leftIdValue,
+          right_id: this.rightIdValue,
+          winner,
+          reason: this.reasonTarget.value
+        })
+      }).then(async r => {
+        if (r.ok) {
+          window.location.reload()
+          return
+        }
+        this.errorTarget.textContent = (await r.text()).trim()
+        this.#saving = false
+      })
+    }
+  }
+
+  window.StimulusApp.register("comparison", ComparisonController)
+})()
@@ -2,7 +2,7 @@
   const { Controller } = Stimulus
 
   class DescriptionController extends Controller {
-    static values = { url: String }
+    static values = { url: String, field: { type: String, default: "description" } }
 
     #timeout = null
 
@@ -24,7 +24,7 @@
       fetch(url, {
         method: "PUT",
         headers: { "Content-Type": "application/json" },
-        body: JSON.stringify({ description: this.element.value })
+        body: JSON.stringify({ [this.fieldValue]: this.element.value })
       })
     }
   }
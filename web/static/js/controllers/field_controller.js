@@ -0,0 +1,37 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Autosaves one project form field, like the description controller, and
+  // marks the input when the server rejects the value.
+  class FieldController extends Controller {
+    static values = { url: String }
+
+    #timeout = null
+
+    disconnect() {
+      clearTimeout(this.#timeout)
+    }
+
+    save() {
+      clearTimeout(this.#timeout)
+      this.#timeout = setTimeout(() => {
+        this.#persist()
+      }, 500)
+    }
+
+    #persist() {
+      const value = this.element.type === "checkbox" ? this.element.checked : this.element.value
+
+      fetch(this.urlValue, {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ value })
+      }).then(async r => {
+        this.element.classList.toggle("invalid", !r.ok)
+        this.element.title = r.ok ? "" : (await r.text()).trim()
+      })
+    }
+  }
+
+  window.StimulusApp.register("field", FieldController)
+})()
@@ -0,0 +1,15 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Applies the viewer's file filter as soon as a select in it changes;
+  // text fields apply on Enter like any form.
+  class FilterController extends Controller {
+    static targets = ["form"]
+
+    submit() {
+      this.formTarget.requestSubmit()
+    }
+  }
+
+  window.StimulusApp.register("filter", FilterController)
+})()
@@ -0,0 +1,197 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Pixels the pointer must move before a press becomes a box selection.
+  const DRAG_THRESHOLD = 5
+
+  // Multi-select in the gallery: Ctrl/Cmd-click toggles an item, Shift-click
+  // selects a range from the last clicked item and dragging draws a box
+  // that selects every item it touches. A plain click opens the file.
+  // Bulk edits apply to the selection, or without one to every file
+  // matching the gallery's filter.
+  class GalleryController extends Controller {
+    static targets = ["item", "box", "toolbar", "count", "bulk", "apply", "error"]
+    static values = { filter: String, total: Number }
+
+    #selected = new Set()
+    #anchor = null
+    #drag = null
+    #dragged = false
+
+    connect() {
+      this.#render()
+    }
+
+    // IDs of the selected files, in gallery order.
+    get selectedIds() {
+      return this.itemTargets
+        .map(item => parseInt(item.dataset.fileId))
+        .filter(id => this.#selected.has(id))
+    }
+
+    click(event) {
+      const item = event.currentTarget.closest("[data-file-id]")
+      const id = parseInt(item.dataset.fileId)
+
+      // The click ending a box selection doesn't open the file.
+      if (this.#dragged) {
+        event.preventDefault()
+        this.#dragged = false
+        return
+      }
+
+      if (event.shiftKey) {
+        event.preventDefault()
+        if (this.#anchor === null) {
+          this.#toggle(id)
+          this.#anchor = id
+        } else {
+          this.#selectRange(this.#anchor, id)
+        }
+      } else if (event.ctrlKey || event.metaKey) {
+        event.preventDefault()
+        this.#toggle(id)
+        this.#anchor = id
+      }
+    }
+
+    startDrag(event) {
+      if (event.button !== 0) return
+      // Keep the browser from selecting text while drawing the box.
+      event.preventDefault()
+
+      const additive = event.shiftKey || event.ctrlKey || event.metaKey
+      this.#drag = {
+        x: event.clientX,
+        y: event.clientY,
+        base: additive ? new Set(this.#selected) : new Set()
+      }
+      this.#dragged = false
+    }
+
+    drag(event) {
+      if (!this.#drag) return
+
+      const { x, y } = this.#drag
+      if (!this.#dragged && Math.hypot(event.clientX - x, event.clientY - y) < DRAG_THRESHOLD) return
+      this.#dragged = true
+
+      const box = {
+        left: Math.min(x, event.clientX),
+        top: Math.min(y, event.clientY),
+        right: Math.max(x, event.clientX),
+        bottom: Math.max(y, event.clientY)
+      }
+      Object.assign(this.boxTarget.style, {
+        left: `${box.left}px`,
+        top: `${box.top}px`,
+        width: `${box.right - box.left}px`,
+        height: `${box.bottom - box.top}px`
+      })
+      this.boxTarget.hidden = false
+
+      this.#selected = new Set(this.#drag.base)
+      this.itemTargets.forEach(item => {
+        const rect = item.getBoundingClientRect()
+        const touches = rect.left < box.right && rect.right > box.left &&
+          rect.top < box.bottom && rect.bottom > box.top
+        if (touches) this.#selected.add(parseInt(item.dataset.fileId))
+      })
+      this.#render()
+    }
+
+    endDrag() {
+      if (!this.#drag) return
+      this.#drag = null
+      this.boxTarget.hidden = true
+      // A click follows the mouseup only when it's over an item; forget the
+      // drag once that click had its chance to see it.
+      setTimeout(() => { this.#dragged = false })
+    }
+
+    keydown(event) {
+      if (event.target.closest("input, textarea, select")) return
+
+      if (event.key === "Escape") {
+        this.clear()
+      } else if (event.key === "a" && (event.ctrlKey || event.metaKey)) {
+        event.preventDefault()
+        this.itemTargets.forEach(item => this.#selected.add(parseInt(item.dataset.fileId)))
+        this.#render()
+      }
+    }
+
+    clear() {
+      this.#selected.clear()
+      this.#anchor = null
+      this.#render()
+    }
+
+    bulk(event) {
+      event.preventDefault()
+
+      const body = Object.fromEntries(new FormData(this.bulkTarget))
+      if (this.#selected.size > 0) {
+        body.ids = this.selectedIds
+      } else if (this.filterValue) {
+        body.filter = this.filterValue
+      } else {
+        this.errorTarget.textContent = "Select files or filter the gallery first."
+        return
+      }
+
+      const request = url => fetch(url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body)
+      }).then(async r => {
+        if (!r.ok) throw new Error((await r.text()).trim())
+        return r.json()
+      })
+
+      request("/bulk/preview")
+        .then(({ count }) => {
+          if (!confirm(`Change ${count} files?`)) return
+          return request("/bulk").then(() => window.location.reload())
+        })
+        .catch(error => { this.errorTarget.textContent = error.message })
+    }
+
+    #toggle(id) {
+      if (this.#selected.has(id)) {
+        this.#selected.delete(id)
+      } else {
+        this.#selected.add(id)
+      }
+      this.#render()
+    }
+
+    #selectRange(fromId, toId) {
+      const ids = this.itemTargets.map(item => parseInt(item.dataset.fileId))
+      const from = ids.indexOf(fromId)
+      const to = ids.indexOf(toId)
+      if (from === -1 || to === -1) return
+
+      ids.slice(Math.min(from, to), Math.max(from, to) + 1).forEach(id => this.#selected.add(id))
+      this.#render()
+    }
+
+    #render() {
+      this.itemTargets.forEach(item => {
+        item.classList.toggle("selected", this.#selected.has(parseInt(item.dataset.fileId)))
+      })
+      this.toolbarTarget.hidden = this.#selected.size === 0
+      this.countTarget.textContent = `${this.#selected.size} selected`
+
+      if (this.#selected.size > 0) {
+        this.applyTarget.textContent = `Apply to ${this.#selected.size} selected`
+      } else if (this.filterValue) {
+        this.applyTarget.textContent = `Apply to all ${this.totalValue} matching`
+      } else {
+        this.applyTarget.textContent = "Apply to selection"
+      }
+    }
+  }
+
+  window.StimulusApp.register("gallery", GalleryController)
+})()
@@ -0,0 +1,64 @@
+(() => {
+  const { Controller } = Stimulus
+
+  class LabelExamplesController extends Controller {
+    static targets = ["input", "list"]
+    static values = { url: String }
+
+    submit(event) {
+      if (event.key !== "Enter") return
+      event.preventDefault()
+
+      const path = this.inputTarget.value.trim()
+      if (!path) return
+
+      fetch(this.urlValue, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ path })
+      }).then(r => {
+        if (!r.ok) {
+          this.inputTarget.classList.add("invalid")
+          return
+        }
+        return r.json().then(file => {
+          this.inputTarget.value = ""
+          this.inputTarget.classList.remove("invalid")
+          this.#appendExample(file)
+        })
+      })
+    }
+
+    remove(event) {
+      const fileId = event.currentTarget.dataset.fileId
+      const item = event.currentTarget.closest(".example-item")
+
+      fetch(`${this.urlValue}/${fileId}`, { method: "DELETE" }).then(r => {
+        if (r.ok && item) item.remove()
+      })
+    }
+
+    #appendExample(file) {
+      if (this.listTarget.querySelector(`[data-file-id="${file.ID}"]`)) return
+
+      const path = this.#escapeHtml(file.Path)
+      const preview = file.MediaType === "image"
+        ? `<img src="/media/${encodeURI(file.Path)}" alt="${path}" loading="lazy">`
+        : `<span class="example-placeholder">${this.#escapeHtml(file.MediaType)}</span>`
+
+      const item = document.createElement("div")
+      item.className = "example-item"
+      item.innerHTML = `<a href="/files/${file.ID}">${preview}<span class="example-path">${path}</span></a>` +
+        `<button class="label-remove" data-action="click->label-examples#remove" data-file-id="${file.ID}">&times;</button>`
+      this.listTarget.appendChild(item)
+    }
+
+    #escapeHtml(str) {
+      const div = document.createElement("div")
+      div.textContent = str
+      return div.innerHTML
+    }
+  }
+
+  window.StimulusApp.register("label-examples", LabelExamplesController)
+})()
@@ -3,7 +3,7 @@
 
   class LabelInputController extends Controller {
     static targets = ["input", "suggestions", "tags"]
-    static values = { url: String }
+    static values = { url: String, fileId: Number, prevFileId: Number }
 
     #activeIndex = -1
     #suggestions = []
@@ -15,12 +15,9 @@
 
     search() {
       clearTimeout(this.#searchTimeout)
-      const query = this.inputTarget.value.trim()
-      if (!query) {
-        this.#hideSuggestions()
-        return
-      }
+      const { name: query } = this.#parseInput()
 
+      // An empty query still returns suggestions based on the previous file.
       this.#searchTimeout = setTimeout(() => {
         this.#fetchSuggestions(query)
       }, 200)
@@ -29,10 +26,11 @@
     submitOrNavigate(event) {
       if (event.key === "Enter") {
         event.preventDefault()
+        const { name, negated } = this.#parseInput()
         if (this.#activeIndex >= 0 && this.#activeIndex < this.#suggestions.length) {
-          this.#addLabel(this.#suggestions[this.#activeIndex].name)
-        } else if (this.inputTarget.value.trim()) {
-          this.#addLabel(this.inputTarget.value.trim())
+          this.#addLabel(this.#suggestions[this.#activeIndex].Name, negated)
+        } else if (name) {
+          this.#addLabel(name, negated)
         }
       } else if (event.key === "ArrowDown") {
         event.preventDefault()
@@ -58,13 +56,34 @@
       })
     }
 
+    dismiss() {
+      clearTimeout(this.#searchTimeout)
+      this.#hideSuggestions()
+    }
+
     preventBlur(event) {
       event.preventDefault()
     }
 
     pickSuggestion(event) {
       const index = parseInt(event.currentTarget.dataset.index)
-      this.#addLabel(this.#suggestions[index].name)
+      this.#addLabel(this.#suggestions[index].Name, this.#parseInput().negated)
+    }
+
+    toggleNegated(event) {
+      const tag = event.currentTarget.closest(".label-tag")
+      const url = this.urlValue
+      if (!url || !tag) return
+
+      fetch(`${url}/${tag.dataset.labelId}`, {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ negated: !tag.classList.contains("negated") })
+      })
+        .then(r => r.json())
+        .then(label => {
+          tag.classList.toggle("negated", label.Negated)
+        })
     }
 
     appendTag(label) {
@@ -72,31 +91,107 @@
       if (this.tagsTarget.querySelector(`[data-label-id="${label.ID}"]`)) return
 
       const span = document.createElement("span")
-      span.className = "label-tag"
+      span.className = label.Negated ? "label-tag negated" : "label-tag"
       span.dataset.labelId = label.ID
-      span.innerHTML = `${this.#escapeHtml(label.Name)} <button class="label-remove" data-action="click->label-input#removeLabel" data-label-id="${label.ID}">&times;</button>`
+      span.dataset.attributes = JSON.stringify(label.Attributes || {})
+      if (label.Definition) span.title = label.Definition
+      const attributes = this.#escapeHtml(this.#formatAttributes(label.Attributes || {}) || "+")
+      span.innerHTML = `<button class="label-polarity" data-action="click->label-input#toggleNegated" title="Toggle not-present">&not;</button>` +
+        `<a href="/labels/${label.ID}" class="label-name">${this.#escapeHtml(label.Name)}</a> ` +
+        `<button class="label-attributes" data-action="click->label-input#editAttributes" title="Edit attributes">${attributes}</button> ` +
+        `<button class="label-remove" data-action="click->label-input#removeLabel" data-label-id="${label.ID}">&times;</button>`
       this.tagsTarget.appendChild(span)
     }
 
-    #addLabel(name) {
+    // Swaps a chip's attribute button for an inline "key=value, key=value" editor.
+    editAttributes(event) {
+      const button = event.currentTarget
+      const tag = button.closest(".label-tag")
+      const url = this.urlValue
+      if (!url || !tag) return
+
+      const labelId = tag.dataset.labelId
+      const input = document.createElement("input")
+      input.type = "text"
+      input.className = "label-attributes-input"
+      input.value = this.#formatAttributes(JSON.parse(tag.dataset.attributes || "{}"))
+      input.placeholder = "key=value, ..."
+      button.replaceWith(input)
+      input.focus()
+
+      fetch(`/labels/${labelId}/attributes`)
+        .then(r => r.json())
+        .then(defs => {
+          if (defs.length === 0) return
+          input.placeholder = defs.map(def => {
+            return def.Type === "enum" ? `${def.Name}=${def.Options.join("|")}` : `${def.Name}=${def.Type}`
+          }).join(", ")
+        })
+
+      const close = () => input.replaceWith(button)
+
+      input.addEventListener("blur", close)
+      input.addEventListener("keydown", e => {
+        if (e.key === "Escape") {
+          close()
+        } else if (e.key === "Enter") {
+          e.preventDefault()
+          fetch(`${url}/${labelId}`, {
+            method: "PUT",
+            headers: { "Content-Type": "application/json" },
+            body: JSON.stringify({ attributes: this.#parseAttributes(input.value) })
+          }).then(r => {
+            if (!r.ok) {
+              return r.text().then(message => {
+                input.classList.add("invalid")
+                input.title = message.trim()
+              })
+            }
+            return r.json().then(data => {
+              tag.dataset.attributes = JSON.stringify(data.Attributes)
+              button.textContent = this.#formatAttributes(data.Attributes) || "+"
+              close()
+            })
+          })
+        }
+      })
+    }
+
+    #addLabel(name, negated) {
       const url = this.urlValue
       if (!url) return
 
       fetch(url, {
         method: "POST",
         headers: { "Content-Type": "application/json" },
-        body: JSON.stringify({ name })
+        body: JSON.stringify({ name, negated })
       })
         .then(r => r.json())
         .then(label => {
+          // Re-adding an existing label may have flipped its polarity.
+          const existing = this.tagsTarget.querySelector(`[data-label-id="${label.ID}"]`)
+          if (existing) existing.classList.toggle("negated", label.Negated)
           this.appendTag(label)
           this.inputTarget.value = ""
           this.#hideSuggestions()
         })
     }
 
+    // A leading "!" marks the label as asserted absent.
+    #parseInput() {
+      const value = this.inputTarget.value.trim()
+      if (value.startsWith("!")) {
+        return { name: value.slice(1).trim(), negated: true }
+      }
+      return { name: value, negated: false }
+    }
+
     #fetchSuggestions(query) {
-      fetch(`/api/labels?q=${encodeURIComponent(query)}`)
+      const params = new URLSearchParams({ q: query })
+      if (this.fileIdValue) params.set("file_id", this.fileIdValue)
+      if (this.prevFileIdValue) params.set("prev_file_id", this.prevFileIdValue)
+
+      fetch(`/api/labels?${params}`)
         .then(r => r.json())
         .then(labels => {
           this.#suggestions = labels
@@ -113,8 +208,11 @@
 
       const html = this.#suggestions.map((label, i) => {
         const highlighted = this.#highlight(label.Name, query)
+        const definition = label.Definition
+          ? `<span class="label-suggestion-definition">${this.#escapeHtml(label.Definition)}</span>`
+          : ""
         const cls = i === this.#activeIndex ? "label-suggestion active" : "label-suggestion"
-        return `<div class="${cls}" data-index="${i}" data-action="mousedown->label-input#preventBlur click->label-input#pickSuggestion">${highlighted}</div>`
+        return `<div class="${cls}" data-index="${i}" data-action="mousedown->label-input#preventBlur click->label-input#pickSuggestion">${highlighted}${definition}</div>`
       }).join("")
 
       this.suggestionsTarget.innerHTML = html
@@ -138,12 +236,42 @@
     }
 
     #highlight(text, query) {
-      const idx = text.toLowerCase().indexOf(query.toLowerCase())
-      if (idx === -1) return this.#escapeHtml(text)
-      const before = text.slice(0, idx)
-      const match = text.slice(idx, idx + query.length)
-      const after = text.slice(idx + query.length)
-      return `${this.#escapeHtml(before)}<mark>${this.#escapeHtml(match)}</mark>${this.#escapeHtml(after)}`
+      if (!query) return this.#escapeHtml(text)
+
+      const lower = text.toLowerCase()
+      const needle = query.toLowerCase()
+      const idx = lower.indexOf(needle)
+      if (idx !== -1) {
+        const before = text.slice(0, idx)
+        const match = text.slice(idx, idx + query.length)
+        const after = text.slice(idx + query.length)
+        return `${this.#escapeHtml(before)}<mark>${this.#escapeHtml(match)}</mark>${this.#escapeHtml(after)}`
+      }
+
+      // Fuzzy match: highlight each query character in order.
+      let pos = 0
+      let html = ""
+      for (const ch of needle) {
+        const at = lower.indexOf(ch, pos)
+        if (at === -1) return this.#escapeHtml(text)
+        html += this.#escapeHtml(text.slice(pos, at)) + `<mark>${this.#escapeHtml(text[at])}</mark>`
+        pos = at + 1
+      }
+      return html + this.#escapeHtml(text.slice(pos))
+    }
+
+    #formatAttributes(attributes) {
+      return Object.keys(attributes).sort().map(key => `${key}=${attributes[key]}`).join(", ")
+    }
+
+    #parseAttributes(text) {
+      const attributes = {}
+      text.split(",").forEach(pair => {
+        const idx = pair.indexOf("=")
+        if (idx <= 0) return
+        attributes[pair.slice(0, idx).trim()] = pair.slice(idx + 1).trim()
+      })
+      return attributes
     }
 
     #escapeHtml(str) {
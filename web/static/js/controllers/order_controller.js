@@ -0,0 +1,19 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Changes the order files are navigated in for the whole project, then
+  // reloads the viewer so the counter and arrows follow it.
+  class OrderController extends Controller {
+    save() {
+      fetch("/order", {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ order: this.element.value })
+      }).then(r => {
+        if (r.ok) window.location.reload()
+      })
+    }
+  }
+
+  window.StimulusApp.register("order", OrderController)
+})()
@@ -0,0 +1,801 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Minimum width/height, as a fraction of the image, for a drawn region to be kept.
+  const MIN_SIZE = 0.005
+
+  // Distance in screen pixels within which a click on the first vertex closes a polygon.
+  const CLOSE_DISTANCE = 8
+
+  const SVG_NS = "http://www.w3.org/2000/svg"
+
+  const HINTS = {
+    box: "Drag on the image to draw a box, or click a region to select it.",
+    polygon: "Click to add vertices. Click the first vertex, double-click or press Enter to close the polygon; Escape cancels.",
+    brush: "Paint the region. Hold Shift or use the right button to erase, then save the mask."
+  }
+
+  const VISIBLE = 2
+  const OCCLUDED = 1
+
+  class RegionsController extends Controller {
+    static targets = ["canvas", "box", "shapes", "brush", "brushSize", "brushActions", "tool", "skeleton", "hint",
+      "detail", "detailLabels", "detailDescription", "labelSection"]
+    static values = { fileId: Number, imageWidth: Number, imageHeight: Number, skeletons: Array }
+
+    #selectedId = null
+    #tool = "box"
+    #drawing = null
+    #moving = null
+    #polygon = null
+    #vertex = null
+    #painting = null
+    #editingMaskId = null
+    #placing = null
+
+    connect() {
+      this.boxTargets.forEach(el => {
+        this.#positionBox(el)
+        this.#renderShape(el)
+        this.#updateCaption(el)
+      })
+    }
+
+    // --- Tools ---
+
+    selectTool(event) {
+      this.#setTool(event.currentTarget.dataset.tool)
+    }
+
+    #setTool(tool) {
+      if (this.#tool === tool) return
+
+      this.#cancelPolygon()
+      this.#cancelKeypoints()
+      if (this.#tool === "brush") this.#closeBrush()
+
+      this.#tool = tool
+      this.toolTargets.forEach(el => el.classList.toggle("active", el.dataset.tool === tool))
+      this.canvasTarget.classList.remove("tool-box", "tool-polygon", "tool-brush", "tool-keypoints")
+      this.canvasTarget.classList.add(`tool-${tool}`)
+      this.hintTarget.textContent = HINTS[tool] || ""
+
+      if (tool === "brush") this.#openBrush()
+      if (tool === "keypoints") this.#startKeypoints()
+      this.#showVertices()
+    }
+
+    changeSkeleton() {
+      if (this.#tool !== "keypoints") return
+      this.#cancelKeypoints()
+      this.#startKeypoints()
+    }
+
+    keydown(event) {
+      if (event.target.closest("input, textarea")) return
+
+      if (this.#placing) {
+        this.#keypointsKeydown(event)
+      } else if (this.#polygon && event.key === "Enter") {
+        event.preventDefault()
+        this.finishPolygon()
+      } else if (this.#polygon && event.key === "Escape") {
+        event.preventDefault()
+        this.#cancelPolygon()
+      }
+    }
+
+    preventMenu(event) {
+      if (this.#tool === "brush") event.preventDefault()
+    }
+
+    // --- Pointer handling for every tool ---
+
+    startDraw(event) {
+      if (event.target.closest(".region-box, .region-vertex")) return
+
+      if (this.#tool === "polygon") {
+        if (event.button !== 0) return
+        event.preventDefault()
+        this.#addPolygonPoint(event)
+        return
+      }
+
+      if (this.#tool === "keypoints") {
+        if (event.button !== 0) return
+        event.preventDefault()
+        this.#placeKeypoint(this.#snap(this.#pointerPosition(event)))
+        return
+      }
+
+      if (this.#tool === "brush") {
+        event.preventDefault()
+        const p = this.#pointerPosition(event)
+        this.#painting = { erase: event.shiftKey || event.button === 2, last: p }
+        this.#paint(p, p)
+        this.canvasTarget.setPointerCapture(event.pointerId)
+        return
+      }
+
+      if (event.button !== 0) return
+      event.preventDefault()
+
+      const start = this.#pointerPosition(event)
+      const el = document.createElement("div")
+      el.className = "region-box drawing"
+      this.canvasTarget.appendChild(el)
+
+      this.#drawing = { start, el, box: { x: start.x, y: start.y, width: 0, height: 0 } }
+      this.canvasTarget.setPointerCapture(event.pointerId)
+    }
+
+    drag(event) {
+      if (this.#drawing) {
+        const p = this.#pointerPosition(event)
+        const { start, el } = this.#drawing
+        const box = {
+          x: Math.min(start.x, p.x),
+          y: Math.min(start.y, p.y),
+          width: Math.abs(p.x - start.x),
+          height: Math.abs(p.y - start.y)
+        }
+        this.#drawing.box = box
+        this.#applyBox(el, box)
+      } else if (this.#moving) {
+        const p = this.#pointerPosition(event)
+        const { el, offset, box } = this.#moving
+        box.x = Math.max(0, Math.min(1 - box.width, p.x - offset.x))
+        box.y = Math.max(0, Math.min(1 - box.height, p.y - offset.y))
+        this.#moving.moved = true
+        this.#applyBox(el, box)
+      } else if (this.#vertex) {
+        const { el, points, index, handle } = this.#vertex
+        points[index] = { ...points[index], ...this.#snap(this.#pointerPosition(event)) }
+        this.#vertex.moved = true
+        this.#applyPoint(handle, points[index])
+        this.#drawShape(el, points)
+      } else if (this.#painting) {
+        const p = this.#pointerPosition(event)
+        this.#paint(this.#painting.last, p)
+        this.#painting.last = p
+      } else if (this.#polygon) {
+        this.#polygon.cursor = this.#snap(this.#pointerPosition(event))
+        this.#drawDraftPolygon()
+      }
+    }
+
+    endDrag() {
+      if (this.#drawing) {
+        const { el, box } = this.#drawing
+        this.#drawing = null
+        el.remove()
+
+        if (box.width < MIN_SIZE || box.height < MIN_SIZE) return
+        this.#createRegion({ kind: "box", ...box })
+      } else if (this.#moving) {
+        const { el, box, moved } = this.#moving
+        this.#moving = null
+        if (!moved) return
+
+        this.#updateRegion(el, box)
+      } else if (this.#vertex) {
+        const { el, points, index, moved } = this.#vertex
+        this.#vertex = null
+
+        if (el.dataset.kind === "keypoints") {
+          // Clicking a keypoint without moving it toggles whether it's visible.
+          if (!moved) points[index].v = points[index].v === VISIBLE ? OCCLUDED : VISIBLE
+          this.#updateRegion(el, { keypoints: points.map(p => [p.x, p.y, p.v]) })
+        } else if (moved) {
+          this.#updateRegion(el, { points: points.map(p => [p.x, p.y]) })
+        }
+      } else if (this.#painting) {
+        this.#painting = null
+      }
+    }
+
+    // --- Selecting and moving an existing region ---
+
+    startMove(event) {
+      if (event.button !== 0) return
+      event.preventDefault()
+      event.stopPropagation()
+
+      const el = event.currentTarget
+      this.#selectRegion(parseInt(el.dataset.regionId))
+
+      // Only boxes are moved by dragging; polygons are edited by their vertices.
+      if (el.dataset.kind !== "box") return
+
+      const p = this.#pointerPosition(event)
+      const box = this.#readBox(el)
+      this.#moving = { el, box, offset: { x: p.x - box.x, y: p.y - box.y }, moved: false }
+      this.canvasTarget.setPointerCapture(event.pointerId)
+    }
+
+    startVertexDrag(event) {
+      if (event.button !== 0) return
+      event.preventDefault()
+      event.stopPropagation()
+
+      const el = this.#findBoxEl(this.#selectedId)
+      if (!el) return
+
+      this.#vertex = {
+        el,
+        handle: event.currentTarget,
+        index: parseInt(event.currentTarget.dataset.index),
+        points: el.dataset.kind === "keypoints" ? this.#readKeypoints(el) : this.#readPoints(el),
+        moved: false
+      }
+      this.canvasTarget.setPointerCapture(event.pointerId)
+    }
+
+    deleteRegion() {
+      if (!this.#selectedId) return
+
+      const id = this.#selectedId
+      const el = this.#findBoxEl(id)
+      fetch(`/regions/${id}`, { method: "DELETE" }).then(r => {
+        if (r.ok) {
+          if (el) el.remove()
+          this.#removeShape(id)
+          this.#selectedId = null
+          this.#showVertices()
+          this.detailTarget.style.display = "none"
+        }
+      })
+    }
+
+    #createRegion(body) {
+      fetch(`/files/${this.fileIdValue}/regions`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body)
+      })
+        .then(r => r.ok ? r.json() : null)
+        .then(region => {
+          if (!region) return
+
+          const el = document.createElement("div")
+          el.className = `region-box kind-${region.Kind}`
+          el.dataset.regionsTarget = "box"
+          el.dataset.regionId = region.ID
+          el.dataset.kind = region.Kind
+          el.dataset.skeletonId = region.SkeletonID
+          el.dataset.description = ""
+          el.dataset.labels = JSON.stringify(region.Labels || [])
+          el.dataset.action = "pointerdown->regions#startMove"
+          el.innerHTML = `<span class="region-caption"></span>`
+          this.#storeRegion(el, region)
+          this.canvasTarget.appendChild(el)
+          this.#positionBox(el)
+          this.#renderShape(el)
+          this.#selectRegion(region.ID)
+        })
+    }
+
+    #updateRegion(el, body) {
+      fetch(`/regions/${el.dataset.regionId}`, {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body)
+      })
+        .then(r => r.ok ? r.json() : null)
+        .then(region => {
+          if (region) this.#storeRegion(el, region)
+          this.#positionBox(el)
+          this.#renderShape(el)
+          this.#showVertices()
+        })
+    }
+
+    #selectRegion(id) {
+      if (this.#selectedId === id) return
+
+      // Sync current region state back to data attributes before switching.
+      this.#syncCurrentRegion()
+
+      this.#selectedId = id
+      this.boxTargets.forEach(el => {
+        el.classList.toggle("selected", parseInt(el.dataset.regionId) === id)
+      })
+      this.shapesTarget.querySelectorAll("[data-region-id]").forEach(shape => {
+        shape.classList.toggle("selected", parseInt(shape.dataset.regionId) === id)
+      })
+      this.#showVertices()
+
+      const el = this.#findBoxEl(id)
+      if (!el) return
+
+      this.detailTarget.style.display = ""
+
+      const labelController = this.application.getControllerForElementAndIdentifier(this.labelSectionTarget, "label-input")
+      if (labelController) {
+        labelController.urlValue = `/regions/${id}/labels`
+      }
+
+      const descController = this.application.getControllerForElementAndIdentifier(this.detailDescriptionTarget, "description")
+      if (descController) {
+        descController.urlValue = `/regions/${id}/description`
+      }
+
+      this.detailDescriptionTarget.value = el.dataset.description || ""
+      const resizeCtrl = this.application.getControllerForElementAndIdentifier(this.detailDescriptionTarget, "auto-resize")
+      if (resizeCtrl) resizeCtrl.resize()
+
+      this.detailLabelsTarget.innerHTML = ""
+      try {
+        const labels = JSON.parse(el.dataset.labels || "[]")
+        if (labelController) labels.forEach(label => labelController.appendTag(label))
+      } catch (e) {
+        // Ignore parse errors.
+      }
+    }
+
+    #syncCurrentRegion() {
+      if (!this.#selectedId) return
+      const el = this.#findBoxEl(this.#selectedId)
+      if (!el) return
+
+      el.dataset.description = this.detailDescriptionTarget.value
+
+      const tags = this.detailLabelsTarget.querySelectorAll(".label-tag")
+      const labels = Array.from(tags).map(tag => ({
+        ID: parseInt(tag.dataset.labelId),
+        Name: tag.querySelector(".label-name").textContent.trim(),
+        Definition: tag.title,
+        Attributes: JSON.parse(tag.dataset.attributes || "{}"),
+        Negated: tag.classList.contains("negated")
+      }))
+      el.dataset.labels = JSON.stringify(labels)
+      this.#updateCaption(el)
+    }
+
+    #updateCaption(el) {
+      const caption = el.querySelector(".region-caption")
+      if (!caption) return
+      try {
+        const labels = JSON.parse(el.dataset.labels || "[]")
+        caption.textContent = labels.filter(l => !l.Negated).map(l => l.Name).join(", ")
+      } catch (e) {
+        caption.textContent = ""
+      }
+    }
+
+    // --- Polygons ---
+
+    #addPolygonPoint(event) {
+      const p = this.#snap(this.#pointerPosition(event))
+
+      if (!this.#polygon) {
+        const line = document.createElementNS(SVG_NS, "polyline")
+        line.classList.add("draft")
+        this.shapesTarget.appendChild(line)
+        this.#polygon = { points: [], cursor: p, line }
+      }
+
+      const { points } = this.#polygon
+      if (points.length >= 3 && this.#nearFirstPoint(event)) {
+        this.finishPolygon()
+        return
+      }
+
+      const last = points[points.length - 1]
+      if (!last || last.x !== p.x || last.y !== p.y) points.push(p)
+      this.#drawDraftPolygon()
+    }
+
+    finishPolygon() {
+      if (!this.#polygon) return
+
+      const { points } = this.#polygon
+      this.#cancelPolygon()
+      if (points.length < 3) return
+
+      this.#createRegion({ kind: "polygon", points: points.map(p => [p.x, p.y]) })
+    }
+
+    #cancelPolygon() {
+      if (!this.#polygon) return
+      this.#polygon.line.remove()
+      this.#polygon = null
+    }
+
+    #nearFirstPoint(event) {
+      const rect = this.canvasTarget.getBoundingClientRect()
+      const first = this.#polygon.points[0]
+      const dx = rect.left + first.x * rect.width - event.clientX
+      const dy = rect.top + first.y * rect.height - event.clientY
+      return Math.hypot(dx, dy) <= CLOSE_DISTANCE
+    }
+
+    #drawDraftPolygon() {
+      const { points, cursor, line } = this.#polygon
+      line.setAttribute("points", [...points, cursor].map(p => `${p.x},${p.y}`).join(" "))
+    }
+
+    #drawPolygon(id, points) {
+      let poly = this.shapesTarget.querySelector(`polygon[data-region-id="${id}"]`)
+      if (!poly) {
+        poly = document.createElementNS(SVG_NS, "polygon")
+        poly.dataset.regionId = id
+        this.shapesTarget.appendChild(poly)
+      }
+      poly.classList.toggle("selected", id === this.#selectedId)
+      poly.setAttribute("points", points.map(p => `${p.x},${p.y}`).join(" "))
+    }
+
+    // Shows draggable handles for the vertices of the selected polygon, or the
+    // placed keypoints of the selected skeleton, in the box tool.
+    #showVertices() {
+      this.canvasTarget.querySelectorAll(".region-vertex").forEach(h => h.remove())
+      if (this.#tool !== "box" || !this.#selectedId) return
+
+      const el = this.#findBoxEl(this.#selectedId)
+      if (!el) return
+
+      let points = []
+      let names = []
+      if (el.dataset.kind === "polygon") {
+        points = this.#readPoints(el)
+      } else if (el.dataset.kind === "keypoints") {
+        points = this.#readKeypoints(el)
+        names = this.#findSkeleton(parseInt(el.dataset.skeletonId))?.Keypoints || []
+      }
+
+      points.forEach((p, i) => {
+        if (p.v === 0) return
+
+        const handle = document.createElement("div")
+        handle.className = "region-vertex"
+        if (p.v === OCCLUDED) handle.classList.add("occluded")
+        if (names[i]) handle.title = `${names[i]} (click to toggle visibility)`
+        handle.dataset.index = i
+        handle.dataset.action = "pointerdown->regions#startVertexDrag"
+        this.#applyPoint(handle, p)
+        this.canvasTarget.appendChild(handle)
+      })
+    }
+
+    #applyPoint(el, p) {
+      el.style.left = p.x * 100 + "%"
+      el.style.top = p.y * 100 + "%"
+    }
+
+    // Snaps a normalized point to the image's pixel grid.
+    #snap(p) {
+      const w = this.imageWidthValue
+      const h = this.imageHeightValue
+      if (!w || !h) return p
+      return { x: Math.round(p.x * w) / w, y: Math.round(p.y * h) / h }
+    }
+
+    // --- Keypoints ---
+
+    #startKeypoints() {
+      const skeleton = this.#findSkeleton(parseInt(this.skeletonTarget.value))
+      if (!skeleton) return
+
+      const group = document.createElementNS(SVG_NS, "g")
+      group.classList.add("draft")
+      this.shapesTarget.appendChild(group)
+      this.#placing = { skeleton, points: [], visibility: VISIBLE, group }
+      this.#updateKeypointsHint()
+    }
+
+    #cancelKeypoints() {
+      if (!this.#placing) return
+      this.#placing.group.remove()
+      this.#placing = null
+    }
+
+    #keypointsKeydown(event) {
+      const key = event.key.toLowerCase()
+      if (key === "v") {
+        this.#placing.visibility = this.#placing.visibility === VISIBLE ? OCCLUDED : VISIBLE
+        this.#updateKeypointsHint()
+      } else if (key === "s") {
+        this.#placeKeypoint(null)
+      } else if (key === "enter") {
+        this.#finishKeypoints()
+      } else if (key === "escape") {
+        this.#cancelKeypoints()
+        this.#startKeypoints()
+      } else {
+        return
+      }
+      event.preventDefault()
+    }
+
+    // Places the next keypoint of the skeleton, or skips it when p is null.
+    #placeKeypoint(p) {
+      const { skeleton, points, visibility, group } = this.#placing
+      points.push(p ? { ...p, v: visibility } : { x: 0, y: 0, v: 0 })
+      this.#drawKeypoints(group, skeleton, points)
+
+      if (points.length >= skeleton.Keypoints.length) {
+        this.#finishKeypoints()
+      } else {
+        this.#updateKeypointsHint()
+      }
+    }
+
+    #finishKeypoints() {
+      const { skeleton, points } = this.#placing
+      this.#cancelKeypoints()
+      this.#startKeypoints()
+
+      while (points.length < skeleton.Keypoints.length) points.push({ x: 0, y: 0, v: 0 })
+      if (!points.some(p => p.v > 0)) return
+
+      this.#createRegion({
+        kind: "keypoints",
+        label_id: skeleton.LabelID,
+        keypoints: points.map(p => [p.x, p.y, p.v])
+      })
+    }
+
+    #updateKeypointsHint() {
+      const { skeleton, points, visibility } = this.#placing
+      const name = skeleton.Keypoints[points.length]
+      const state = visibility === VISIBLE ? "visible" : "occluded"
+      this.hintTarget.textContent = `Click to place ${name} (${state}). V toggles occluded, S skips, Enter finishes, Escape restarts.`
+    }
+
+    #drawKeypoints(group, skeleton, points) {
+      group.replaceChildren()
+
+      skeleton.Edges.forEach(([a, b]) => {
+        const p = points[a]
+        const q = points[b]
+        if (!p || !q || p.v === 0 || q.v === 0) return
+        group.appendChild(this.#svgLine(p, q, "edge"))
+      })
+
+      points.forEach(p => {
+        if (p.v === 0) return
+        group.appendChild(this.#svgLine(p, p, p.v === OCCLUDED ? "keypoint occluded" : "keypoint"))
+      })
+    }
+
+    // A zero-length line with round caps draws an undistorted dot in the
+    // stretched viewBox.
+    #svgLine(p, q, className) {
+      const line = document.createElementNS(SVG_NS, "line")
+      line.setAttribute("class", className)
+      line.setAttribute("x1", p.x)
+      line.setAttribute("y1", p.y)
+      line.setAttribute("x2", q.x)
+      line.setAttribute("y2", q.y)
+      return line
+    }
+
+    #findSkeleton(labelId) {
+      return this.skeletonsValue.find(s => s.LabelID === labelId)
+    }
+
+    // --- Brush masks ---
+
+    #openBrush() {
+      const brush = this.brushTarget
+      brush.width = this.imageWidthValue
+      brush.height = this.imageHeightValue
+      brush.style.display = ""
+      this.brushActionsTarget.style.display = ""
+
+      // Continue painting the selected mask, or start a new one.
+      const el = this.#selectedId && this.#findBoxEl(this.#selectedId)
+      this.#editingMaskId = null
+      if (el && el.dataset.kind === "mask") {
+        this.#editingMaskId = this.#selectedId
+        this.#drawMask(brush, JSON.parse(el.dataset.mask))
+        this.#removeShape(this.#selectedId)
+      }
+    }
+
+    #closeBrush() {
+      const el = this.#editingMaskId && this.#findBoxEl(this.#editingMaskId)
+      if (el) this.#renderShape(el)
+
+      this.#editingMaskId = null
+      this.#painting = null
+      this.brushTarget.style.display = "none"
+      this.brushActionsTarget.style.display = "none"
+    }
+
+    saveMask() {
+      const mask = this.#encodeMask()
+      if (!mask) return
+
+      if (this.#editingMaskId) {
+        const el = this.#findBoxEl(this.#editingMaskId)
+        this.#updateRegion(el, { mask })
+      } else {
+        this.#createRegion({ kind: "mask", mask })
+      }
+      this.#editingMaskId = null
+      this.#setTool("box")
+    }
+
+    cancelMask() {
+      this.#setTool("box")
+    }
+
+    #paint(from, to) {
+      const ctx = this.brushTarget.getContext("2d")
+      const rect = this.canvasTarget.getBoundingClientRect()
+      const scale = this.imageWidthValue / rect.width
+      const radius = Math.max(0.5, (parseInt(this.brushSizeTarget.value) / 2) * scale)
+
+      ctx.globalCompositeOperation = this.#painting.erase ? "destination-out" : "source-over"
+      ctx.strokeStyle = ctx.fillStyle = "rgb(233, 69, 96)"
+      ctx.lineWidth = radius * 2
+      ctx.lineCap = "round"
+      ctx.beginPath()
+      ctx.moveTo(from.x * this.imageWidthValue, from.y * this.imageHeightValue)
+      ctx.lineTo(to.x * this.imageWidthValue, to.y * this.imageHeightValue)
+      ctx.stroke()
+    }
+
+    // Encodes the painted pixels as a column-major COCO run-length encoding.
+    #encodeMask() {
+      const w = this.imageWidthValue
+      const h = this.imageHeightValue
+      const data = this.brushTarget.getContext("2d").getImageData(0, 0, w, h).data
+
+      const counts = []
+      let painted = false
+      let run = 0
+      let any = false
+      for (let x = 0; x < w; x++) {
+        for (let y = 0; y < h; y++) {
+          const on = data[(y * w + x) * 4 + 3] >= 128
+          if (on !== painted) {
+            counts.push(run)
+            run = 0
+            painted = on
+          }
+          if (on) any = true
+          run++
+        }
+      }
+      counts.push(run)
+
+      return any ? { size: [h, w], counts } : null
+    }
+
+    // Paints a mask's runs onto a canvas of the image's size.
+    #drawMask(canvas, mask) {
+      const ctx = canvas.getContext("2d")
+      const image = ctx.createImageData(mask.Width, mask.Height)
+      let pos = 0
+      mask.Counts.forEach((count, i) => {
+        if (i % 2 === 1) {
+          for (let p = pos; p < pos + count; p++) {
+            const x = Math.floor(p / mask.Height)
+            const y = p % mask.Height
+            const o = (y * mask.Width + x) * 4
+            image.data[o] = 233
+            image.data[o + 1] = 69
+            image.data[o + 2] = 96
+            image.data[o + 3] = 255
+          }
+        }
+        pos += count
+      })
+      ctx.putImageData(image, 0, 0)
+    }
+
+    // --- Shapes drawn for polygon and mask regions ---
+
+    #renderShape(el) {
+      const id = parseInt(el.dataset.regionId)
+
+      if (el.dataset.kind === "polygon") {
+        this.#drawPolygon(id, this.#readPoints(el))
+      } else if (el.dataset.kind === "keypoints") {
+        this.#drawShape(el, this.#readKeypoints(el))
+      } else if (el.dataset.kind === "mask" && el.dataset.mask) {
+        let canvas = this.canvasTarget.querySelector(`canvas.region-mask[data-region-id="${id}"]`)
+        if (!canvas) {
+          canvas = document.createElement("canvas")
+          canvas.className = "region-mask"
+          canvas.dataset.regionId = id
+          this.shapesTarget.before(canvas)
+        }
+        const mask = JSON.parse(el.dataset.mask)
+        canvas.width = mask.Width
+        canvas.height = mask.Height
+        this.#drawMask(canvas, mask)
+      }
+    }
+
+    // Redraws a polygon or skeleton from points being edited.
+    #drawShape(el, points) {
+      const id = parseInt(el.dataset.regionId)
+      if (el.dataset.kind === "polygon") {
+        this.#drawPolygon(id, points)
+        return
+      }
+
+      const skeleton = this.#findSkeleton(parseInt(el.dataset.skeletonId))
+      if (!skeleton) return
+
+      let group = this.shapesTarget.querySelector(`g[data-region-id="${id}"]`)
+      if (!group) {
+        group = document.createElementNS(SVG_NS, "g")
+        group.dataset.regionId = id
+        this.shapesTarget.appendChild(group)
+      }
+      group.classList.toggle("selected", id === this.#selectedId)
+      this.#drawKeypoints(group, skeleton, points)
+    }
+
+    #removeShape(id) {
+      this.canvasTarget.querySelectorAll(`[data-region-id="${id}"]:not(.region-box)`).forEach(s => s.remove())
+    }
+
+    // --- Helpers ---
+
+    #findBoxEl(id) {
+      return this.boxTargets.find(el => parseInt(el.dataset.regionId) === id)
+    }
+
+    #pointerPosition(event) {
+      const rect = this.canvasTarget.getBoundingClientRect()
+      return {
+        x: Math.max(0, Math.min(1, (event.clientX - rect.left) / rect.width)),
+        y: Math.max(0, Math.min(1, (event.clientY - rect.top) / rect.height))
+      }
+    }
+
+    #readBox(el) {
+      return {
+        x: parseFloat(el.dataset.x),
+        y: parseFloat(el.dataset.y),
+        width: parseFloat(el.dataset.width),
+        height: parseFloat(el.dataset.height)
+      }
+    }
+
+    #readPoints(el) {
+      try {
+        return JSON.parse(el.dataset.points || "[]").map(p => ({ x: p.X, y: p.Y }))
+      } catch (e) {
+        return []
+      }
+    }
+
+    #readKeypoints(el) {
+      try {
+        return JSON.parse(el.dataset.keypoints || "[]").map(k => ({ x: k.X, y: k.Y, v: k.Visibility }))
+      } catch (e) {
+        return []
+      }
+    }
+
+    #storeRegion(el, region) {
+      el.dataset.x = region.X
+      el.dataset.y = region.Y
+      el.dataset.width = region.Width
+      el.dataset.height = region.Height
+      el.dataset.points = JSON.stringify(region.Points || [])
+      el.dataset.mask = region.Mask && region.Mask.Counts ? JSON.stringify(region.Mask) : ""
+      el.dataset.keypoints = JSON.stringify(region.Keypoints || [])
+    }
+
+    #positionBox(el) {
+      this.#applyBox(el, this.#readBox(el))
+    }
+
+    #applyBox(el, box) {
+      el.style.left = box.x * 100 + "%"
+      el.style.top = box.y * 100 + "%"
+      el.style.width = box.width * 100 + "%"
+      el.style.height = box.height * 100 + "%"
+    }
+  }
+
+  window.StimulusApp.register("regions", RegionsController)
+})()
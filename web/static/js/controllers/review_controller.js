@@ -0,0 +1,39 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Approves the file, or rejects it back to the annotators with the reason
+  // typed in, then reloads the viewer.
+  class ReviewController extends Controller {
+    static targets = ["reason", "error"]
+    static values = { url: String }
+
+    approve() {
+      this.#send({ decision: "approved" })
+    }
+
+    reject() {
+      const reason = this.reasonTarget.value.trim()
+      if (!reason) {
+        this.errorTarget.textContent = "Give a reason for the rejection."
+        this.reasonTarget.focus()
+        return
+      }
+      this.#send({ decision: "rejected", reason })
+    }
+
+    #send(body) {
+      fetch(this.urlValue, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(body)
+      })
+        .then(async r => {
+          if (!r.ok) throw new Error((await r.text()).trim())
+          window.location.reload()
+        })
+        .catch(error => { this.errorTarget.textContent = error.message })
+    }
+  }
+
+  window.StimulusApp.register("review", ReviewController)
+})()
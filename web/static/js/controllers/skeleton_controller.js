@@ -0,0 +1,81 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // The 17 keypoints and edges of the COCO person skeleton.
+  const PERSON_KEYPOINTS = [
+    "nose", "left_eye", "right_eye", "left_ear", "right_ear",
+    "left_shoulder", "right_shoulder", "left_elbow", "right_elbow", "left_wrist", "right_wrist",
+    "left_hip", "right_hip", "left_knee", "right_knee", "left_ankle", "right_ankle"
+  ]
+  const PERSON_EDGES = [
+    ["left_ankle", "left_knee"], ["left_knee", "left_hip"], ["right_ankle", "right_knee"],
+    ["right_knee", "right_hip"], ["left_hip", "right_hip"], ["left_shoulder", "left_hip"],
+    ["right_shoulder", "right_hip"], ["left_shoulder", "right_shoulder"], ["left_shoulder", "left_elbow"],
+    ["right_shoulder", "right_elbow"], ["left_elbow", "left_wrist"], ["right_elbow", "right_wrist"],
+    ["left_eye", "right_eye"], ["nose", "left_eye"], ["nose", "right_eye"],
+    ["left_eye", "left_ear"], ["right_eye", "right_ear"], ["left_ear", "left_shoulder"],
+    ["right_ear", "right_shoulder"]
+  ]
+
+  class SkeletonController extends Controller {
+    static targets = ["keypoints", "edges", "error"]
+    static values = { url: String }
+
+    save() {
+      const keypoints = this.keypointsTarget.value.split("\n").map(k => k.trim()).filter(k => k)
+
+      const edges = []
+      for (const line of this.edgesTarget.value.split("\n").map(l => l.trim()).filter(l => l)) {
+        const pair = this.#parseEdge(line)
+        const from = pair ? keypoints.indexOf(pair[0]) : -1
+        const to = pair ? keypoints.indexOf(pair[1]) : -1
+        if (from < 0 || to < 0) {
+          this.errorTarget.textContent = `Edge "${line}" must connect two listed keypoints`
+          return
+        }
+        edges.push([from, to])
+      }
+
+      fetch(this.urlValue, {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ keypoints, edges })
+      }).then(r => {
+        if (!r.ok) {
+          return r.text().then(message => {
+            this.errorTarget.textContent = message.trim()
+          })
+        }
+        this.errorTarget.textContent = ""
+      })
+    }
+
+    remove() {
+      fetch(this.urlValue, { method: "DELETE" }).then(r => {
+        if (!r.ok) {
+          return r.text().then(message => {
+            this.errorTarget.textContent = message.trim()
+          })
+        }
+        this.errorTarget.textContent = ""
+        this.keypointsTarget.value = ""
+        this.edgesTarget.value = ""
+      })
+    }
+
+    usePersonTemplate() {
+      this.keypointsTarget.value = PERSON_KEYPOINTS.join("\n")
+      this.edgesTarget.value = PERSON_EDGES.map(([a, b]) => `${a} - ${b}`).join("\n")
+    }
+
+    // Parses "from - to"; a bare "from-to" is accepted when the names have no dashes.
+    #parseEdge(line) {
+      let parts = line.split(/\s+-\s+/)
+      if (parts.length !== 2) parts = line.split("-")
+      if (parts.length !== 2) return null
+      return parts.map(p => p.trim())
+    }
+  }
+
+  window.StimulusApp.register("skeleton", SkeletonController)
+})()
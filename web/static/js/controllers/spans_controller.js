@@ -0,0 +1,205 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Characters of a span's text shown in the detail panel header.
+  const EXCERPT_LENGTH = 80
+
+  // Spans are stored as Unicode code point offsets, so the document is kept
+  // as an array of code points rather than a (UTF-16) string.
+  class SpansController extends Controller {
+    static targets = ["source", "document", "hint", "detail", "detailText",
+      "detailLabels", "detailDescription", "labelSection"]
+    static values = { fileId: Number, spans: Array }
+
+    #text = []
+    #spans = []
+    #selectedId = null
+
+    connect() {
+      if (!this.hasSourceTarget) return
+      this.#text = Array.from(JSON.parse(this.sourceTarget.textContent))
+      this.#spans = this.spansValue
+      this.#render()
+    }
+
+    create() {
+      const range = this.#selectionOffsets()
+      if (!range) {
+        this.hintTarget.textContent = "Select some text in the document first."
+        return
+      }
+
+      fetch(`/files/${this.fileIdValue}/spans`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ start_offset: range.start, end_offset: range.end })
+      })
+        .then(r => r.ok ? r.json() : null)
+        .then(span => {
+          if (!span) return
+          window.getSelection().removeAllRanges()
+          this.#spans.push(span)
+          this.#spans.sort((a, b) => a.StartOffset - b.StartOffset || a.EndOffset - b.EndOffset)
+          this.#select(span.ID)
+        })
+    }
+
+    select(event) {
+      // Dragging to select text also ends in a click; don't treat it as one.
+      if (!window.getSelection().isCollapsed) return
+
+      const piece = event.target.closest("[data-span-ids]")
+      if (!piece) {
+        this.#select(null)
+        return
+      }
+
+      // Repeated clicks cycle through overlapping spans, shortest first.
+      const candidates = piece.dataset.spanIds.split(",")
+        .map(id => this.#find(parseInt(id)))
+        .sort((a, b) => (a.EndOffset - a.StartOffset) - (b.EndOffset - b.StartOffset))
+      const i = candidates.findIndex(span => span.ID === this.#selectedId)
+      this.#select(candidates[(i + 1) % candidates.length].ID)
+    }
+
+    deleteSpan() {
+      const id = this.#selectedId
+      if (!id) return
+
+      fetch(`/spans/${id}`, { method: "DELETE" }).then(r => {
+        if (!r.ok) return
+        this.#selectedId = null
+        this.#spans = this.#spans.filter(span => span.ID !== id)
+        this.detailTarget.style.display = "none"
+        this.#render()
+      })
+    }
+
+    keydown(event) {
+      if (event.key === "Escape" && this.#selectedId && !event.target.closest("input, textarea")) {
+        this.#select(null)
+      }
+    }
+
+    #select(id) {
+      // Sync current span state back before switching.
+      this.#syncSelected()
+
+      this.#selectedId = id
+      this.#render()
+
+      const span = this.#find(id)
+      if (!span) {
+        this.detailTarget.style.display = "none"
+        return
+      }
+
+      this.detailTarget.style.display = ""
+      const excerpt = this.#text.slice(span.StartOffset, span.EndOffset).join("")
+      this.detailTextTarget.textContent = excerpt.length > EXCERPT_LENGTH
+        ? `“${excerpt.slice(0, EXCERPT_LENGTH)}…”`
+        : `“${excerpt}”`
+      this.detailTextTarget.title = `Characters ${span.StartOffset}–${span.EndOffset}`
+
+      const labelController = this.application.getControllerForElementAndIdentifier(this.labelSectionTarget, "label-input")
+      if (labelController) {
+        labelController.urlValue = `/spans/${id}/labels`
+      }
+
+      const descController = this.application.getControllerForElementAndIdentifier(this.detailDescriptionTarget, "description")
+      if (descController) {
+        descController.urlValue = `/spans/${id}/description`
+      }
+
+      this.detailDescriptionTarget.value = span.Description || ""
+      const resizeCtrl = this.application.getControllerForElementAndIdentifier(this.detailDescriptionTarget, "auto-resize")
+      if (resizeCtrl) resizeCtrl.resize()
+
+      this.detailLabelsTarget.innerHTML = ""
+      if (labelController) (span.Labels || []).forEach(label => labelController.appendTag(label))
+    }
+
+    #syncSelected() {
+      const span = this.#find(this.#selectedId)
+      if (!span) return
+
+      span.Description = this.detailDescriptionTarget.value
+
+      const tags = this.detailLabelsTarget.querySelectorAll(".label-tag")
+      span.Labels = Array.from(tags).map(tag => ({
+        ID: parseInt(tag.dataset.labelId),
+        Name: tag.querySelector(".label-name").textContent.trim(),
+        Definition: tag.title,
+        Attributes: JSON.parse(tag.dataset.attributes || "{}"),
+        Negated: tag.classList.contains("negated")
+      }))
+    }
+
+    // Splits the document at every span boundary and wraps each piece
+    // covered by spans in a mark listing them, so overlapping spans nest.
+    #render() {
+      const bounds = new Set([0, this.#text.length])
+      this.#spans.forEach(span => {
+        bounds.add(span.StartOffset)
+        bounds.add(span.EndOffset)
+      })
+      const points = Array.from(bounds).sort((a, b) => a - b)
+
+      const fragment = document.createDocumentFragment()
+      for (let i = 0; i < points.length - 1; i++) {
+        const [start, end] = [points[i], points[i + 1]]
+        const text = this.#text.slice(start, end).join("")
+        const covering = this.#spans.filter(span => span.StartOffset <= start && span.EndOffset >= end)
+        if (covering.length === 0) {
+          fragment.appendChild(document.createTextNode(text))
+          continue
+        }
+
+        const mark = document.createElement("mark")
+        mark.className = "text-span"
+        mark.classList.toggle("selected", covering.some(span => span.ID === this.#selectedId))
+        mark.style.setProperty("--span-depth", covering.length)
+        mark.dataset.spanIds = covering.map(span => span.ID).join(",")
+        mark.title = covering.map(span => this.#caption(span)).join("\n")
+        mark.textContent = text
+        fragment.appendChild(mark)
+      }
+
+      this.documentTarget.replaceChildren(fragment)
+    }
+
+    #caption(span) {
+      const names = (span.Labels || []).filter(l => !l.Negated).map(l => l.Name)
+      return names.length ? names.join(", ") : "Unlabeled span"
+    }
+
+    #find(id) {
+      return this.#spans.find(span => span.ID === id)
+    }
+
+    // Returns the selected text as code point offsets into the document,
+    // without surrounding whitespace.
+    #selectionOffsets() {
+      const selection = window.getSelection()
+      if (!selection.rangeCount || selection.isCollapsed) return null
+
+      const range = selection.getRangeAt(0)
+      if (!this.documentTarget.contains(range.commonAncestorContainer)) return null
+
+      const offset = (node, nodeOffset) => {
+        const prefix = document.createRange()
+        prefix.setStart(this.documentTarget, 0)
+        prefix.setEnd(node, nodeOffset)
+        return Array.from(prefix.toString()).length
+      }
+
+      let start = offset(range.startContainer, range.startOffset)
+      let end = offset(range.endContainer, range.endOffset)
+      while (start < end && /\s/.test(this.#text[start])) start++
+      while (end > start && /\s/.test(this.#text[end - 1])) end--
+      return start < end ? { start, end } : null
+    }
+  }
+
+  window.StimulusApp.register("spans", SpansController)
+})()
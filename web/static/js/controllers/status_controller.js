@@ -0,0 +1,42 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Sets the file's workflow status from the header select or with the
+  // number keys 1–5, and refreshes the project progress bar.
+  class StatusController extends Controller {
+    static targets = ["select", "progress", "bar"]
+    static values = { url: String }
+
+    save() {
+      const status = this.selectTarget.value
+
+      fetch(this.urlValue, {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ status })
+      })
+        .then(r => r.ok ? r.json() : null)
+        .then(progress => {
+          if (!progress) return
+          this.selectTarget.className = `status-select status-${status}`
+          this.barTarget.style.width = `${progress.percent.toFixed(1)}%`
+          this.progressTarget.title = `${progress.finished} of ${progress.total} files done or skipped`
+        })
+    }
+
+    shortcut(event) {
+      // Don't change the status when typing in an input or textarea.
+      if (event.target.closest("input, textarea, select")) return
+      if (event.ctrlKey || event.metaKey || event.altKey) return
+
+      const option = this.selectTarget.options[parseInt(event.key) - 1]
+      if (!option || !/^[1-9]$/.test(event.key)) return
+
+      event.preventDefault()
+      this.selectTarget.value = option.value
+      this.save()
+    }
+  }
+
+  window.StimulusApp.register("status", StatusController)
+})()
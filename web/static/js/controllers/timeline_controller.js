@@ -1,21 +1,33 @@
 (() => {
   const { Controller } = Stimulus
 
+  // Length of a segment added with the button, starting at the playhead.
+  const DEFAULT_SEGMENT_MS = 2000
+
+  // Shortest segment that can be drawn or resized to.
+  const MIN_SEGMENT_MS = 100
+
+  // Height of one row of the segment lane; overlapping segments stack.
+  const SEGMENT_ROW_PX = 14
+
   class TimelineController extends Controller {
-    static targets = ["track", "playhead", "keyframe", "detail", "detailTime", "detailLabels", "detailDescription", "deleteBtn", "labelSection", "media"]
-    static values = { fileId: Number }
+    static targets = ["track", "playhead", "keyframe", "segmentLane", "segment", "detail", "detailTime", "detailLabels", "detailDescription", "detailTranscript", "detailLanguage", "deleteBtn", "labelSection", "media"]
+    static values = { fileId: Number, width: Number, height: Number, durationMs: Number }
 
-    #selectedId = null
+    #selected = null
     #duration = 0
     #scrubbing = false
     #wasPlaying = false
+    #segmentDrag = null
 
     connect() {
       // If metadata is already loaded (cached media).
       if (this.hasMediaTarget && this.mediaTarget.duration) {
         this.#duration = this.mediaTarget.duration * 1000
         this.#positionKeyframes()
+        this.#positionSegments()
         this.#autoSelectFirst()
+        this.#reportMetadata()
       }
     }
 
@@ -24,7 +36,29 @@
     initializeTimeline() {
       this.#duration = this.mediaTarget.duration * 1000
       this.#positionKeyframes()
+      this.#positionSegments()
       this.#autoSelectFirst()
+      this.#reportMetadata()
+    }
+
+    // Stores the frame size and duration, which only the browser can read,
+    // for exports and sorting.
+    #reportMetadata() {
+      const media = this.mediaTarget
+      const width = media.videoWidth || 0
+      const height = media.videoHeight || 0
+      const durationMs = Math.round(this.#duration)
+      if (!isFinite(durationMs)) return
+      if (width === this.widthValue && height === this.heightValue && durationMs === this.durationMsValue) return
+
+      this.widthValue = width
+      this.heightValue = height
+      this.durationMsValue = durationMs
+      fetch(`/files/${this.fileIdValue}/metadata`, {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ width, height, duration_ms: durationMs })
+      })
     }
 
     updatePlayhead() {
@@ -88,19 +122,20 @@
           dot.dataset.timestampMs = kf.TimestampMs
           dot.dataset.pinned = "false"
           dot.dataset.description = ""
+          dot.dataset.transcript = ""
+          dot.dataset.language = ""
           dot.dataset.labels = "[]"
           dot.dataset.action = "click->timeline#selectKeyframe"
           dot.title = kf.TimestampMs + "ms"
           this.trackTarget.appendChild(dot)
           this.#positionKeyframes()
-          this.#selectKeyframe(kf.ID)
+          this.#select(dot)
         })
     }
 
     selectKeyframe(event) {
       event.stopPropagation()
-      const id = parseInt(event.currentTarget.dataset.keyframeId)
-      this.#selectKeyframe(id)
+      this.#select(event.currentTarget)
 
       // Seek media to keyframe time.
       if (this.hasMediaTarget) {
@@ -109,54 +144,205 @@
       }
     }
 
-    deleteKeyframe() {
-      if (!this.#selectedId) return
-
-      const kfEl = this.#findKeyframeEl(this.#selectedId)
-      if (kfEl && kfEl.dataset.pinned === "true") return
+    deleteSelected() {
+      const el = this.#selected
+      if (!el || el.dataset.pinned === "true") return
 
-      fetch(`/keyframes/${this.#selectedId}`, { method: "DELETE" }).then(r => {
+      fetch(this.#urlFor(el), { method: "DELETE" }).then(r => {
         if (r.ok) {
-          if (kfEl) kfEl.remove()
-          this.#selectedId = null
+          el.remove()
+          this.#selected = null
           if (this.hasDetailTarget) this.detailTarget.style.display = "none"
+          this.#positionSegments()
         }
       })
     }
 
-    #selectKeyframe(id) {
-      // Sync current keyframe state back to data attributes before switching.
-      this.#syncCurrentKeyframe()
+    // --- Segments (bars on the lane below the track) ---
 
-      this.#selectedId = id
+    addSegment() {
+      if (!this.#duration || !this.hasMediaTarget) return
+      const endMs = Math.min(Math.round(this.mediaTarget.currentTime * 1000) + DEFAULT_SEGMENT_MS, Math.floor(this.#duration))
+      const startMs = Math.max(0, Math.min(Math.round(this.mediaTarget.currentTime * 1000), endMs - DEFAULT_SEGMENT_MS))
+      this.#createSegment(startMs, endMs)
+    }
 
-      // Highlight the selected keyframe.
-      this.keyframeTargets.forEach(el => {
-        el.classList.toggle("selected", parseInt(el.dataset.keyframeId) === id)
+    // Dragging on an empty part of the lane draws a new segment.
+    startSegment(event) {
+      if (event.target !== this.segmentLaneTarget || !this.#duration) return
+      const ms = this.#pointerMs(event)
+      this.#segmentDrag = { mode: "create", x: event.clientX, anchorMs: ms, el: null }
+    }
+
+    // Dragging a bar moves it; dragging one of its handles resizes it.
+    grabSegment(event) {
+      event.stopPropagation()
+      if (!this.#duration) return
+      const el = event.currentTarget
+      this.#segmentDrag = {
+        mode: event.target.dataset.edge || "move",
+        x: event.clientX,
+        el,
+        startMs: parseInt(el.dataset.startMs),
+        endMs: parseInt(el.dataset.endMs),
+        moved: false
+      }
+      event.preventDefault()
+    }
+
+    dragSegment(event) {
+      const drag = this.#segmentDrag
+      if (!drag) return
+      if (Math.abs(event.clientX - drag.x) < 3 && !drag.moved) return
+      drag.moved = true
+
+      if (drag.mode === "create") {
+        if (!drag.el) drag.el = this.#appendSegmentEl({ ID: 0, StartMs: drag.anchorMs, EndMs: drag.anchorMs, Description: "", Labels: [] })
+        const ms = this.#pointerMs(event)
+        this.#setSegmentRange(drag.el, Math.min(ms, drag.anchorMs), Math.max(ms, drag.anchorMs))
+        return
+      }
+
+      const deltaMs = Math.round(((event.clientX - drag.x) / this.segmentLaneTarget.getBoundingClientRect().width) * this.#duration)
+      const duration = Math.floor(this.#duration)
+      let { startMs, endMs } = drag
+      if (drag.mode === "move") {
+        const length = endMs - startMs
+        startMs = Math.max(0, Math.min(duration - length, startMs + deltaMs))
+        endMs = startMs + length
+      } else if (drag.mode === "start") {
+        startMs = Math.max(0, Math.min(endMs - MIN_SEGMENT_MS, startMs + deltaMs))
+      } else {
+        endMs = Math.min(duration, Math.max(startMs + MIN_SEGMENT_MS, endMs + deltaMs))
+      }
+      this.#setSegmentRange(drag.el, startMs, endMs)
+    }
+
+    endSegmentDrag() {
+      const drag = this.#segmentDrag
+      if (!drag) return
+      this.#segmentDrag = null
+
+      if (drag.mode === "create") {
+        if (!drag.el) return
+        const startMs = parseInt(drag.el.dataset.startMs)
+        const endMs = parseInt(drag.el.dataset.endMs)
+        drag.el.remove()
+        if (endMs - startMs >= MIN_SEGMENT_MS) this.#createSegment(startMs, endMs)
+        return
+      }
+
+      if (!drag.moved) {
+        // A plain click selects the segment and seeks to its start.
+        this.#select(drag.el)
+        if (this.hasMediaTarget) this.mediaTarget.currentTime = drag.startMs / 1000
+        return
+      }
+
+      const startMs = parseInt(drag.el.dataset.startMs)
+      const endMs = parseInt(drag.el.dataset.endMs)
+      fetch(this.#urlFor(drag.el), {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ start_ms: startMs, end_ms: endMs })
+      }).then(r => {
+        if (!r.ok) this.#setSegmentRange(drag.el, drag.startMs, drag.endMs)
+      })
+      if (this.#selected === drag.el) this.#showDetailTime(drag.el)
+    }
+
+    #createSegment(startMs, endMs) {
+      fetch(`/files/${this.fileIdValue}/segments`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ start_ms: startMs, end_ms: endMs })
+      })
+        .then(r => r.ok ? r.json() : null)
+        .then(seg => {
+          if (!seg) return
+          this.#select(this.#appendSegmentEl(seg))
+        })
+    }
+
+    #appendSegmentEl(seg) {
+      const bar = document.createElement("div")
+      bar.className = "timeline-segment"
+      bar.dataset.timelineTarget = "segment"
+      bar.dataset.segmentId = seg.ID
+      bar.dataset.startMs = seg.StartMs
+      bar.dataset.endMs = seg.EndMs
+      bar.dataset.description = seg.Description
+      bar.dataset.transcript = seg.Transcript
+      bar.dataset.language = seg.Language
+      bar.dataset.labels = JSON.stringify(seg.Labels || [])
+      bar.dataset.action = "pointerdown->timeline#grabSegment"
+      bar.innerHTML = `<span class="timeline-segment-handle start" data-edge="start"></span><span class="timeline-segment-handle end" data-edge="end"></span>`
+      this.segmentLaneTarget.appendChild(bar)
+      this.#setSegmentRange(bar, seg.StartMs, seg.EndMs)
+      return bar
+    }
+
+    #setSegmentRange(el, startMs, endMs) {
+      el.dataset.startMs = startMs
+      el.dataset.endMs = endMs
+      el.title = `${this.#formatTime(startMs)} – ${this.#formatTime(endMs)}`
+      this.#positionSegments()
+    }
+
+    // Places every bar at its time range, stacking overlapping segments
+    // onto separate rows.
+    #positionSegments() {
+      if (!this.#duration || !this.hasSegmentLaneTarget) return
+
+      const rowEnds = []
+      const bars = this.segmentTargets.slice().sort((a, b) => parseInt(a.dataset.startMs) - parseInt(b.dataset.startMs))
+      bars.forEach(el => {
+        const startMs = parseInt(el.dataset.startMs)
+        const endMs = parseInt(el.dataset.endMs)
+        let row = rowEnds.findIndex(end => end <= startMs)
+        if (row === -1) row = rowEnds.length
+        rowEnds[row] = endMs
+
+        el.style.left = (startMs / this.#duration) * 100 + "%"
+        el.style.width = ((endMs - startMs) / this.#duration) * 100 + "%"
+        el.style.top = row * SEGMENT_ROW_PX + "px"
       })
+      this.segmentLaneTarget.style.height = Math.max(1, rowEnds.length) * SEGMENT_ROW_PX + "px"
+    }
+
+    #pointerMs(event) {
+      const rect = this.segmentLaneTarget.getBoundingClientRect()
+      const ratio = Math.max(0, Math.min(1, (event.clientX - rect.left) / rect.width))
+      return Math.round(ratio * this.#duration)
+    }
 
-      const kfEl = this.#findKeyframeEl(id)
-      if (!kfEl) return
+    #select(el) {
+      // Sync current selection state back to data attributes before switching.
+      this.#syncSelected()
 
-      const isPinned = kfEl.dataset.pinned === "true"
-      const ms = parseInt(kfEl.dataset.timestampMs)
+      this.#selected = el
+
+      // Highlight the selected keyframe or segment.
+      this.keyframeTargets.concat(this.segmentTargets).forEach(other => {
+        other.classList.toggle("selected", other === el)
+      })
+
+      const isPinned = el.dataset.pinned === "true"
 
       // Show detail panel.
       if (this.hasDetailTarget) {
         this.detailTarget.style.display = ""
       }
-      if (this.hasDetailTimeTarget) {
-        this.detailTimeTarget.textContent = this.#formatTime(ms)
-      }
+      this.#showDetailTime(el)
       if (this.hasDeleteBtnTarget) {
         this.deleteBtnTarget.style.display = isPinned ? "none" : ""
       }
 
-      // Update the label section URL for this keyframe.
+      // Update the label section URL for this keyframe or segment.
       if (this.hasLabelSectionTarget) {
         const labelController = this.application.getControllerForElementAndIdentifier(this.labelSectionTarget, "label-input")
         if (labelController) {
-          labelController.urlValue = `/keyframes/${id}/labels`
+          labelController.urlValue = `${this.#urlFor(el)}/labels`
         }
       }
 
@@ -164,31 +350,56 @@
       if (this.hasDetailDescriptionTarget) {
         const descController = this.application.getControllerForElementAndIdentifier(this.detailDescriptionTarget, "description")
         if (descController) {
-          descController.urlValue = `/keyframes/${id}/description`
+          descController.urlValue = `${this.#urlFor(el)}/description`
         }
+        this.detailDescriptionTarget.placeholder = el.dataset.segmentId ? "Segment description..." : "Keyframe description..."
       }
 
-      // Load keyframe details (labels + description).
-      this.#loadKeyframeDetail(id)
+      // Update transcript and language URLs; both save to the same endpoint.
+      this.detailTranscriptTargets.concat(this.detailLanguageTargets).forEach(target => {
+        const controller = this.application.getControllerForElementAndIdentifier(target, "description")
+        if (controller) controller.urlValue = `${this.#urlFor(el)}/transcript`
+      })
+
+      // Load details (labels + description).
+      this.#loadDetail(el)
+    }
+
+    #showDetailTime(el) {
+      if (!this.hasDetailTimeTarget) return
+      this.detailTimeTarget.textContent = el.dataset.segmentId
+        ? `${this.#formatTime(parseInt(el.dataset.startMs))} – ${this.#formatTime(parseInt(el.dataset.endMs))}`
+        : this.#formatTime(parseInt(el.dataset.timestampMs))
     }
 
-    #loadKeyframeDetail(id) {
-      const kfEl = this.#findKeyframeEl(id)
-      if (!kfEl) return
+    #urlFor(el) {
+      return el.dataset.segmentId ? `/segments/${el.dataset.segmentId}` : `/keyframes/${el.dataset.keyframeId}`
+    }
 
+    #loadDetail(el) {
       // Load description from data attribute.
       if (this.hasDetailDescriptionTarget) {
-        this.detailDescriptionTarget.value = kfEl.dataset.description || ""
+        this.detailDescriptionTarget.value = el.dataset.description || ""
         // Trigger auto-resize without firing input (which would trigger a save).
         const resizeCtrl = this.application.getControllerForElementAndIdentifier(this.detailDescriptionTarget, "auto-resize")
         if (resizeCtrl) resizeCtrl.resize()
       }
 
+      // Load transcript and language.
+      if (this.hasDetailTranscriptTarget) {
+        this.detailTranscriptTarget.value = el.dataset.transcript || ""
+        const resizeCtrl = this.application.getControllerForElementAndIdentifier(this.detailTranscriptTarget, "auto-resize")
+        if (resizeCtrl) resizeCtrl.resize()
+      }
+      if (this.hasDetailLanguageTarget) {
+        this.detailLanguageTarget.value = el.dataset.language || ""
+      }
+
       // Load labels from data attribute.
       if (this.hasDetailLabelsTarget) {
         this.detailLabelsTarget.innerHTML = ""
         try {
-          const labels = JSON.parse(kfEl.dataset.labels || "[]")
+          const labels = JSON.parse(el.dataset.labels || "[]")
           const labelController = this.hasLabelSectionTarget
             ? this.application.getControllerForElementAndIdentifier(this.labelSectionTarget, "label-input")
             : null
@@ -204,21 +415,38 @@
     }
 
     #autoSelectFirst() {
-      if (this.#selectedId) return
+      if (this.#selected) return
+
+      // A search result links to a keyframe as #keyframe-<id>.
+      const linked = window.location.hash.match(/^#keyframe-(\d+)$/)
+      const target = linked && this.keyframeTargets.find(el => el.dataset.keyframeId === linked[1])
+      if (target) {
+        this.#select(target)
+        this.mediaTarget.currentTime = parseInt(target.dataset.timestampMs) / 1000
+        this.updatePlayhead()
+        return
+      }
+
       if (this.keyframeTargets.length > 0) {
-        const firstId = parseInt(this.keyframeTargets[0].dataset.keyframeId)
-        this.#selectKeyframe(firstId)
+        this.#select(this.keyframeTargets[0])
       }
     }
 
-    #syncCurrentKeyframe() {
-      if (!this.#selectedId) return
-      const kfEl = this.#findKeyframeEl(this.#selectedId)
-      if (!kfEl) return
+    #syncSelected() {
+      const el = this.#selected
+      if (!el) return
 
       // Sync description.
       if (this.hasDetailDescriptionTarget) {
-        kfEl.dataset.description = this.detailDescriptionTarget.value
+        el.dataset.description = this.detailDescriptionTarget.value
+      }
+
+      // Sync transcript and language.
+      if (this.hasDetailTranscriptTarget) {
+        el.dataset.transcript = this.detailTranscriptTarget.value
+      }
+      if (this.hasDetailLanguageTarget) {
+        el.dataset.language = this.detailLanguageTarget.value
       }
 
       // Sync labels from the tags container.
@@ -226,16 +454,15 @@
         const tags = this.detailLabelsTarget.querySelectorAll(".label-tag")
         const labels = Array.from(tags).map(tag => ({
           ID: parseInt(tag.dataset.labelId),
-          Name: tag.childNodes[0].textContent.trim()
+          Name: tag.querySelector(".label-name").textContent.trim(),
+          Definition: tag.title,
+          Attributes: JSON.parse(tag.dataset.attributes || "{}"),
+          Negated: tag.classList.contains("negated")
         }))
-        kfEl.dataset.labels = JSON.stringify(labels)
+        el.dataset.labels = JSON.stringify(labels)
       }
     }
 
-    #findKeyframeEl(id) {
-      return this.keyframeTargets.find(el => parseInt(el.dataset.keyframeId) === id)
-    }
-
     #positionKeyframes() {
       if (!this.#duration) return
       this.keyframeTargets.forEach(el => {
@@ -0,0 +1,332 @@
+(() => {
+  const { Controller } = Stimulus
+
+  // Minimum width/height, as a fraction of the frame, for a drawn box to be kept.
+  const MIN_SIZE = 0.005
+
+  class TracksController extends Controller {
+    static targets = ["media", "overlay", "list", "labelInput", "nameInput", "hint"]
+    static values = { fileId: Number, tracks: Array }
+
+    #tracks = []
+    #selectedId = null
+    #drawing = null
+    #moving = null
+    #frame = null
+
+    connect() {
+      this.#tracks = this.tracksValue
+      this.#renderList()
+      if (this.mediaTarget.readyState >= 1) this.loaded()
+    }
+
+    disconnect() {
+      cancelAnimationFrame(this.#frame)
+    }
+
+    // --- Media events ---
+
+    loaded() {
+      this.#fitOverlay()
+      this.#renderList()
+      this.render()
+    }
+
+    // Renders boxes continuously while playing; timeupdate alone is too coarse.
+    play() {
+      const tick = () => {
+        this.render()
+        if (!this.mediaTarget.paused) this.#frame = requestAnimationFrame(tick)
+      }
+      this.#frame = requestAnimationFrame(tick)
+    }
+
+    render() {
+      if (this.#drawing || this.#moving) return
+      const ms = this.#currentMs()
+
+      this.overlayTarget.querySelectorAll(".track-box").forEach(el => el.remove())
+      this.#tracks.forEach(track => {
+        const box = this.#boxAt(track, ms)
+        if (!box) return
+
+        const el = document.createElement("div")
+        el.className = "track-box"
+        el.classList.toggle("selected", track.ID === this.#selectedId)
+        el.classList.toggle("key", track.Boxes.some(b => b.TimestampMs === ms))
+        el.dataset.trackId = track.ID
+        el.style.setProperty("--track-hue", this.#hue(track))
+        el.innerHTML = `<span class="track-box-caption"></span>`
+        el.querySelector(".track-box-caption").textContent = this.#title(track)
+        this.#positionBox(el, box)
+        this.overlayTarget.appendChild(el)
+      })
+
+      this.#updateRemoveButtons(ms)
+    }
+
+    // --- Track list ---
+
+    create(event) {
+      event.preventDefault()
+      const label = this.labelInputTarget.value.trim()
+      if (!label) return
+
+      fetch(`/files/${this.fileIdValue}/tracks`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ label, name: this.nameInputTarget.value })
+      })
+        .then(r => r.ok ? r.json() : null)
+        .then(track => {
+          if (!track) return
+          this.#tracks.push(track)
+          this.labelInputTarget.value = ""
+          this.nameInputTarget.value = ""
+          this.#select(track.ID)
+        })
+    }
+
+    select(event) {
+      if (event.target.closest("button, .track-key")) return
+      const id = parseInt(event.currentTarget.dataset.trackId)
+      this.#select(this.#selectedId === id ? null : id)
+    }
+
+    seekKey(event) {
+      this.mediaTarget.pause()
+      this.mediaTarget.currentTime = parseInt(event.currentTarget.dataset.ms) / 1000
+    }
+
+    removeBox(event) {
+      const id = parseInt(event.currentTarget.dataset.trackId)
+      fetch(`/tracks/${id}/boxes/${this.#currentMs()}`, { method: "DELETE" })
+        .then(r => r.ok ? r.json() : null)
+        .then(track => track && this.#replace(track))
+    }
+
+    remove(event) {
+      const id = parseInt(event.currentTarget.dataset.trackId)
+      if (!confirm("Delete this track and all of its boxes?")) return
+
+      fetch(`/tracks/${id}`, { method: "DELETE" }).then(r => {
+        if (!r.ok) return
+        this.#tracks = this.#tracks.filter(t => t.ID !== id)
+        if (this.#selectedId === id) this.#selectedId = null
+        this.#renderList()
+        this.render()
+      })
+    }
+
+    keydown(event) {
+      if (event.key === "Escape" && this.#selectedId && !event.target.closest("input, textarea")) {
+        this.#select(null)
+      }
+    }
+
+    #select(id) {
+      this.#selectedId = id
+      this.overlayTarget.classList.toggle("editing", id !== null)
+      this.#renderList()
+      this.render()
+    }
+
+    #renderList() {
+      const duration = this.mediaTarget.duration * 1000
+      this.listTarget.innerHTML = ""
+
+      this.#tracks.forEach(track => {
+        const row = document.createElement("div")
+        row.className = "track-row"
+        row.classList.toggle("selected", track.ID === this.#selectedId)
+        row.dataset.trackId = track.ID
+        row.dataset.action = "click->tracks#select"
+        row.style.setProperty("--track-hue", this.#hue(track))
+        row.innerHTML = `
+          <span class="track-swatch"></span>
+          <span class="track-title"></span>
+          <span class="track-lane"></span>
+          <button class="btn-delete" data-action="click->tracks#removeBox" data-track-id="${track.ID}" data-remove-box>Remove box here</button>
+          <button class="btn-delete" data-action="click->tracks#remove" data-track-id="${track.ID}">Delete</button>`
+        row.querySelector(".track-title").textContent = `${this.#title(track)} (${track.Boxes.length} boxes)`
+
+        const lane = row.querySelector(".track-lane")
+        if (duration && track.Boxes.length > 1) {
+          const [first, last] = [track.Boxes[0], track.Boxes[track.Boxes.length - 1]]
+          const span = document.createElement("span")
+          span.className = "track-span"
+          span.style.left = (first.TimestampMs / duration) * 100 + "%"
+          span.style.width = ((last.TimestampMs - first.TimestampMs) / duration) * 100 + "%"
+          lane.appendChild(span)
+        }
+        track.Boxes.forEach(b => {
+          const key = document.createElement("span")
+          key.className = "track-key"
+          key.dataset.ms = b.TimestampMs
+          key.dataset.action = "click->tracks#seekKey"
+          key.title = `${b.TimestampMs}ms`
+          if (duration) key.style.left = (b.TimestampMs / duration) * 100 + "%"
+          lane.appendChild(key)
+        })
+
+        this.listTarget.appendChild(row)
+      })
+
+      this.hintTarget.textContent = this.#selectedId
+        ? "Drag over the video to set the track's box at the current time, or drag its box to move it. Escape stops editing."
+        : "Select a track to set its boxes. Boxes between two set ones are interpolated."
+      this.#updateRemoveButtons(this.#currentMs())
+    }
+
+    #updateRemoveButtons(ms) {
+      this.listTarget.querySelectorAll("[data-remove-box]").forEach(btn => {
+        const track = this.#find(parseInt(btn.dataset.trackId))
+        btn.disabled = !track || !track.Boxes.some(b => b.TimestampMs === ms)
+      })
+    }
+
+    // --- Drawing and moving boxes over the video ---
+
+    startDraw(event) {
+      if (!this.#selectedId || event.button !== 0) return
+      event.preventDefault()
+      this.mediaTarget.pause()
+
+      const p = this.#pointerPosition(event)
+      const boxEl = event.target.closest(".track-box")
+      if (boxEl && parseInt(boxEl.dataset.trackId) === this.#selectedId) {
+        const box = this.#boxAt(this.#find(this.#selectedId), this.#currentMs())
+        this.#moving = { el: boxEl, start: p, origin: box, box }
+        this.overlayTarget.setPointerCapture(event.pointerId)
+        return
+      }
+
+      const el = document.createElement("div")
+      el.className = "track-box drawing"
+      el.style.setProperty("--track-hue", this.#hue(this.#find(this.#selectedId)))
+      this.overlayTarget.appendChild(el)
+      this.#drawing = { start: p, el, box: { X: p.x, Y: p.y, Width: 0, Height: 0 } }
+      this.overlayTarget.setPointerCapture(event.pointerId)
+    }
+
+    draw(event) {
+      if (this.#drawing) {
+        const p = this.#pointerPosition(event)
+        const { start, el } = this.#drawing
+        this.#drawing.box = {
+          X: Math.min(start.x, p.x),
+          Y: Math.min(start.y, p.y),
+          Width: Math.abs(p.x - start.x),
+          Height: Math.abs(p.y - start.y)
+        }
+        this.#positionBox(el, this.#drawing.box)
+      } else if (this.#moving) {
+        const p = this.#pointerPosition(event)
+        const { start, origin, el } = this.#moving
+        this.#moving.box = {
+          ...origin,
+          X: Math.max(0, Math.min(1 - origin.Width, origin.X + p.x - start.x)),
+          Y: Math.max(0, Math.min(1 - origin.Height, origin.Y + p.y - start.y))
+        }
+        this.#positionBox(el, this.#moving.box)
+      }
+    }
+
+    endDraw() {
+      const action = this.#drawing || this.#moving
+      if (!action) return
+      this.#drawing = null
+      this.#moving = null
+
+      const box = action.box
+      if (action.el.classList.contains("drawing")) action.el.remove()
+      if (box.Width < MIN_SIZE || box.Height < MIN_SIZE || box === action.origin) {
+        this.render()
+        return
+      }
+
+      fetch(`/tracks/${this.#selectedId}/boxes/${this.#currentMs()}`, {
+        method: "PUT",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ x: box.X, y: box.Y, width: box.Width, height: box.Height })
+      })
+        .then(r => r.ok ? r.json() : null)
+        .then(track => track ? this.#replace(track) : this.render())
+    }
+
+    // --- Helpers ---
+
+    #replace(track) {
+      this.#tracks = this.#tracks.map(t => t.ID === track.ID ? track : t)
+      this.#renderList()
+      this.render()
+    }
+
+    #find(id) {
+      return this.#tracks.find(t => t.ID === id)
+    }
+
+    // Mirrors db.Track.BoxAt: linear interpolation between the surrounding boxes.
+    #boxAt(track, ms) {
+      const boxes = track.Boxes
+      const i = boxes.findIndex(b => b.TimestampMs >= ms)
+      if (i === -1) return null
+      if (boxes[i].TimestampMs === ms) return boxes[i]
+      if (i === 0) return null
+
+      const prev = boxes[i - 1]
+      const next = boxes[i]
+      const f = (ms - prev.TimestampMs) / (next.TimestampMs - prev.TimestampMs)
+      const lerp = (a, b) => a + (b - a) * f
+      return {
+        X: lerp(prev.X, next.X),
+        Y: lerp(prev.Y, next.Y),
+        Width: lerp(prev.Width, next.Width),
+        Height: lerp(prev.Height, next.Height)
+      }
+    }
+
+    #currentMs() {
+      return Math.round(this.mediaTarget.currentTime * 1000)
+    }
+
+    #title(track) {
+      return track.Name ? `${track.Label} · ${track.Name}` : `${track.Label} #${track.ID}`
+    }
+
+    #hue(track) {
+      return (track.ID * 67) % 360
+    }
+
+    #positionBox(el, box) {
+      el.style.left = box.X * 100 + "%"
+      el.style.top = box.Y * 100 + "%"
+      el.style.width = box.Width * 100 + "%"
+      el.style.height = box.Height * 100 + "%"
+    }
+
+    #pointerPosition(event) {
+      const rect = this.overlayTarget.getBoundingClientRect()
+      return {
+        x: Math.max(0, Math.min(1, (event.clientX - rect.left) / rect.width)),
+        y: Math.max(0, Math.min(1, (event.clientY - rect.top) / rect.height))
+      }
+    }
+
+    // Places the overlay over the picture itself, excluding any letterboxing.
+    #fitOverlay() {
+      const video = this.mediaTarget
+      if (!video.videoWidth || !video.videoHeight) return
+
+      const scale = Math.min(video.clientWidth / video.videoWidth, video.clientHeight / video.videoHeight)
+      const width = video.videoWidth * scale
+      const height = video.videoHeight * scale
+      this.overlayTarget.style.left = video.offsetLeft + (video.clientWidth - width) / 2 + "px"
+      this.overlayTarget.style.top = video.offsetTop + (video.clientHeight - height) / 2 + "px"
+      this.overlayTarget.style.width = width + "px"
+      this.overlayTarget.style.height = height + "px"
+    }
+  }
+
+  window.StimulusApp.register("tracks", TracksController)
+})()